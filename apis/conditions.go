@@ -37,6 +37,8 @@ type Condition struct {
 	Reason             ConditionReason        `json:"reason,omitempty"`
 	Message            string                 `json:"message,omitempty"`
 	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	// ObservedGeneration is the generation of the object the condition was set for, if relevant
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // IsTrue Condition whether the condition status is "True".
@@ -131,7 +133,8 @@ func (conditions *Conditions) SetCondition(newCond Condition) bool {
 			}
 			changed := condition.Status != newCond.Status ||
 				condition.Reason != newCond.Reason ||
-				condition.Message != newCond.Message
+				condition.Message != newCond.Message ||
+				condition.ObservedGeneration != newCond.ObservedGeneration
 			(*conditions)[i] = newCond
 			return changed
 		}
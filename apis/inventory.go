@@ -0,0 +1,95 @@
+package apis
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceRef references a resource managed by an operator
+// +k8s:deepcopy-gen=true
+type ResourceRef struct {
+	// Group: API group of the resource, empty for the core group
+	Group string `json:"group,omitempty"`
+
+	// Version: API version of the resource
+	Version string `json:"version"`
+
+	// Kind: kind of the resource
+	Kind string `json:"kind"`
+
+	// Namespace: namespace of the resource, empty for cluster scoped resources
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name: name of the resource
+	Name string `json:"name"`
+}
+
+// NewResourceRef returns a reference to the resource with the given kind, namespace and name
+func NewResourceRef(gvk schema.GroupVersionKind, namespace, name string) ResourceRef {
+	return ResourceRef{
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Kind:      gvk.Kind,
+		Namespace: namespace,
+		Name:      name,
+	}
+}
+
+// GroupVersionKind returns the kind of the referenced resource
+func (ref ResourceRef) GroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: ref.Group, Version: ref.Version, Kind: ref.Kind}
+}
+
+// Inventory is the set of resources managed by an operator on behalf of a custom resource.
+// It is kept sorted, so that it renders in a stable way in the status
+type Inventory []ResourceRef
+
+// Contains returns whether the inventory references the given resource
+func (inventory Inventory) Contains(ref ResourceRef) bool {
+	for _, r := range inventory {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds the reference to the inventory. It returns false if the reference was already there
+func (inventory *Inventory) Add(ref ResourceRef) bool {
+	if inventory.Contains(ref) {
+		return false
+	}
+	*inventory = append(*inventory, ref)
+	sort.Slice(*inventory, func(a, b int) bool {
+		return (*inventory)[a].less((*inventory)[b])
+	})
+	return true
+}
+
+// Remove removes the reference from the inventory. It returns false if the reference was not found
+func (inventory *Inventory) Remove(ref ResourceRef) bool {
+	for i, r := range *inventory {
+		if r == ref {
+			*inventory = append((*inventory)[:i], (*inventory)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (ref ResourceRef) less(other ResourceRef) bool {
+	if ref.Group != other.Group {
+		return ref.Group < other.Group
+	}
+	if ref.Version != other.Version {
+		return ref.Version < other.Version
+	}
+	if ref.Kind != other.Kind {
+		return ref.Kind < other.Kind
+	}
+	if ref.Namespace != other.Namespace {
+		return ref.Namespace < other.Namespace
+	}
+	return ref.Name < other.Name
+}
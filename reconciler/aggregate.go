@@ -0,0 +1,119 @@
+package reconciler
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AggregateError collects the errors of independent reconcile steps. ManageError sets the conditions of all its
+// ConditionErrors in one status write and handles it according to the most urgent of its errors: errors neither
+// retriable nor terminal are returned, conflicts requeued, retriable errors retried after the shortest retryAfter.
+// It is terminal only if all its errors are terminal
+type AggregateError struct {
+	Errs []error
+}
+
+// NewAggregateError returns an AggregateError of the non nil errs, or nil if there are none
+func NewAggregateError(errs ...error) error {
+	agg := &AggregateError{}
+	for _, err := range errs {
+		agg.Add(err)
+	}
+	return agg.ErrorOrNil()
+}
+
+// Add appends err to the aggregate, if not nil
+func (e *AggregateError) Add(err error) {
+	if err != nil {
+		e.Errs = append(e.Errs, err)
+	}
+}
+
+// ErrorOrNil returns the aggregate if it holds errors, nil otherwise
+func (e *AggregateError) ErrorOrNil() error {
+	if e == nil || len(e.Errs) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *AggregateError) Error() string {
+	messages := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether any of the errors of the aggregate matches target
+func (e *AggregateError) Is(target error) bool {
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors of the aggregate that matches target and, if one is found, sets target to
+// that error value
+func (e *AggregateError) As(target interface{}) bool {
+	for _, err := range e.Errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// asAggregate returns the non empty AggregateError in the chain of err, if any
+func asAggregate(err error) (*AggregateError, bool) {
+	var agg *AggregateError
+	if !errors.As(err, &agg) || len(agg.Errs) == 0 {
+		return nil, false
+	}
+	return agg, true
+}
+
+// conditionErrors returns the ConditionError of err or, if it is an AggregateError, the ones of its errors
+func conditionErrors(err error) []*ConditionError {
+	if agg, ok := asAggregate(err); ok {
+		conditionErrs := []*ConditionError{}
+		for _, err := range agg.Errs {
+			conditionErrs = append(conditionErrs, conditionErrors(err)...)
+		}
+		return conditionErrs
+	}
+	var conditionErr *ConditionError
+	if errors.As(err, &conditionErr) {
+		return []*ConditionError{conditionErr}
+	}
+	return nil
+}
+
+// errorClassRank orders the error classes from the most to the least urgent
+var errorClassRank = map[ErrorClass]int{
+	ErrorClassDefault:      0,
+	ErrorClassRequeue:      1,
+	ErrorClassRetriable:    2,
+	ErrorClassBackoff:      3,
+	ErrorClassNotRetriable: 4,
+	ErrorClassTerminal:     5,
+}
+
+// classifyAggregate returns the most urgent class of the members of agg according to classify and, for
+// retriable ones, the shortest interval after which to retry
+func classifyAggregate(agg *AggregateError, classify func(error) (ErrorClass, time.Duration)) (ErrorClass, time.Duration) {
+	class, retryAfter := ErrorClassTerminal, time.Duration(0)
+	for _, err := range agg.Errs {
+		memberClass, memberRetryAfter := classify(err)
+		switch {
+		case errorClassRank[memberClass] < errorClassRank[class]:
+			class, retryAfter = memberClass, memberRetryAfter
+		case memberClass == class && memberRetryAfter < retryAfter:
+			retryAfter = memberRetryAfter
+		}
+	}
+	return class, retryAfter
+}
@@ -0,0 +1,129 @@
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestAggregateError(t *testing.T) {
+	missingSecret := NewRetriableConditionError("SecretReady", "SecretMissing", time.Minute, errors.New("secret missing"))
+	badHost := NewTerminalConditionError("IngressReady", "InvalidHost", errors.New("bad host"))
+	slowDependency := NewRetriableError(10*time.Second, errors.New("dependency down"))
+
+	tests := []struct {
+		name           string
+		err            error
+		wantClass      ErrorClass
+		wantRetryAfter time.Duration
+		wantConditions int
+	}{
+		{
+			name:           "shortest retry after",
+			err:            NewAggregateError(missingSecret, badHost, slowDependency),
+			wantClass:      ErrorClassRetriable,
+			wantRetryAfter: 10 * time.Second,
+			wantConditions: 2,
+		},
+		{
+			name:           "wrapped aggregate",
+			err:            fmt.Errorf("reconcile failed: %w", NewAggregateError(missingSecret, badHost)),
+			wantClass:      ErrorClassRetriable,
+			wantRetryAfter: time.Minute,
+			wantConditions: 2,
+		},
+		{
+			name:           "terminal only if all are",
+			err:            NewAggregateError(badHost, NewTerminalError(errors.New("bad port"))),
+			wantClass:      ErrorClassTerminal,
+			wantConditions: 1,
+		},
+		{
+			name:           "unclassified error",
+			err:            NewAggregateError(missingSecret, errors.New("failed")),
+			wantClass:      ErrorClassDefault,
+			wantConditions: 1,
+		},
+		{
+			name:           "nested aggregate",
+			err:            NewAggregateError(NewAggregateError(missingSecret), badHost),
+			wantClass:      ErrorClassRetriable,
+			wantRetryAfter: time.Minute,
+			wantConditions: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(nil, scheme.Scheme, nil, "test")
+			class, retryAfter := r.classifyError(tt.err)
+			if class != tt.wantClass || retryAfter != tt.wantRetryAfter {
+				t.Errorf("classifyError() = %q, %s, want %q, %s", class, retryAfter, tt.wantClass, tt.wantRetryAfter)
+			}
+			if got := len(conditionErrors(tt.err)); got != tt.wantConditions {
+				t.Errorf("conditionErrors() returned %d errors, want %d", got, tt.wantConditions)
+			}
+
+			wantRetriable := tt.wantClass == ErrorClassRetriable
+			if got := IsRetriable(tt.err); got != wantRetriable {
+				t.Errorf("IsRetriable() = %v, want %v", got, wantRetriable)
+			}
+			if got, ok := RetryAfter(tt.err); ok != wantRetriable || got != tt.wantRetryAfter {
+				t.Errorf("RetryAfter() = %s, %v, want %s, %v", got, ok, tt.wantRetryAfter, wantRetriable)
+			}
+			if got := IsTerminal(tt.err); got != (tt.wantClass == ErrorClassTerminal) {
+				t.Errorf("IsTerminal() = %v", got)
+			}
+			if _, ok := AsConditionError(tt.err); !ok {
+				t.Error("AsConditionError() found no ConditionError")
+			}
+		})
+	}
+}
+
+func TestNewAggregateErrorWithoutErrors(t *testing.T) {
+	if err := NewAggregateError(nil, nil); err != nil {
+		t.Errorf("NewAggregateError() = %v, want nil", err)
+	}
+}
+
+func TestAggregateErrorIsAs(t *testing.T) {
+	cause := errors.New("secret missing")
+	missingSecret := NewRetriableConditionError("SecretReady", "SecretMissing", time.Minute, cause)
+	err := fmt.Errorf("reconcile failed: %w", NewAggregateError(errors.New("failed"), missingSecret))
+
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is() = false for the cause of a member")
+	}
+	if errors.Is(err, errors.New("secret missing")) {
+		t.Error("errors.Is() = true for another error")
+	}
+	var conditionErr *ConditionError
+	if !errors.As(err, &conditionErr) || conditionErr.Reason != "SecretMissing" {
+		t.Errorf("errors.As() = %v, want the SecretMissing ConditionError", conditionErr)
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	// a terminal error wrapping a not retriable one is both
+	err := NewTerminalError(fmt.Errorf("invalid spec: %w", NewNotRetriableError(errors.New("bad host"))))
+	if !IsTerminal(err) {
+		t.Error("IsTerminal() = false")
+	}
+	if !IsNotRetriable(err) {
+		t.Error("IsNotRetriable() = false")
+	}
+	if IsRetriable(err) {
+		t.Error("IsRetriable() = true")
+	}
+
+	err = NewNotRetriableError(NewBackoffError(errors.New("dependency down")))
+	if !IsRetriable(err) || !IsNotRetriable(err) {
+		t.Errorf("IsRetriable() = %v, IsNotRetriable() = %v, want both true", IsRetriable(err), IsNotRetriable(err))
+	}
+	if retryAfter, ok := RetryAfter(err); !ok || retryAfter != 0 {
+		t.Errorf("RetryAfter() = %s, %v, want 0, true", retryAfter, ok)
+	}
+}
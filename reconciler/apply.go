@@ -0,0 +1,89 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// AppliedCondition is the condition type set on the owner when a child resource could not be applied
+	AppliedCondition apis.ConditionType = "Applied"
+	// FieldManagerConflictReason is the condition reason reported when server-side apply hits a field manager conflict
+	FieldManagerConflictReason apis.ConditionReason = "FieldManagerConflict"
+)
+
+// ApplyResource creates or patches a resource using server-side apply with the controller name as field manager.
+// Fields set by other managers (HPAs, admission webhooks, etc.) are left untouched.
+// Pass client.ForceOwnership to take over fields conflicting with other managers.
+// if owner is not nil, the owner field is set
+// if obj namespace is "", the namespace field of the owner is assigned
+// It returns OperationResultCreated, OperationResultPatched, or OperationResultUnchanged when the apply
+// did not change the resource version
+func (r *Reconciler) ApplyResource(ctx context.Context, owner, obj Resource, opts ...client.PatchOption) (OperationResult, error) {
+	log := r.loggerFor(obj)
+	if err := r.setOwner(owner, obj); err != nil {
+		log.Error(err, "unable to set owner")
+		return OperationResultNone, err
+	}
+
+	// apply patches must carry apiVersion and kind, which typed objects usually lack
+	gvk, err := apiutil.GVKForObject(obj, r.GetScheme())
+	if err != nil {
+		log.Error(err, "could not resolve object kind")
+		return OperationResultNone, err
+	}
+
+	// the live resource version tells apart creations, changes and no-op applies
+	existing := obj.DeepCopyObject().(Resource)
+	err = r.client.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Error(err, "unable to get object")
+		return OperationResultNone, err
+	}
+	found := err == nil
+
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetManagedFields(nil)
+
+	log.V(2).Info("Applying resource")
+	err = r.patch(ctx, nil, obj, client.Apply, append([]client.PatchOption{client.FieldOwner(r.name)}, opts...)...)
+	if err != nil && apierrors.IsConflict(err) {
+		log.Info("Field manager conflict", "Error", err.Error())
+		return OperationResultNone, NewConditionErrorWithReason(
+			AppliedCondition,
+			FieldManagerConflictReason,
+			fmt.Errorf("unable to apply %s %s/%s: %w", gvk.Kind, obj.GetNamespace(), obj.GetName(), err),
+		)
+	} else if err != nil {
+		log.Error(err, "unable to apply object")
+		return OperationResultNone, err
+	}
+	r.recordInventory(owner, obj)
+
+	switch {
+	case !found:
+		return OperationResultCreated, nil
+	case obj.GetResourceVersion() == existing.GetResourceVersion():
+		return OperationResultUnchanged, nil
+	}
+	return OperationResultPatched, nil
+}
+
+// ApplyResources operates as ApplyResource, but on an array of resources
+func (r *Reconciler) ApplyResources(ctx context.Context, owner Resource, objs []Resource, opts ...client.PatchOption) (ResourceResults, error) {
+	results := make(ResourceResults, 0, len(objs))
+	for _, obj := range objs {
+		res, err := r.ApplyResource(ctx, owner, obj, opts...)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, newResourceResult(r.scheme, obj, res))
+	}
+	return results, nil
+}
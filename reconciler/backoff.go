@@ -0,0 +1,146 @@
+package reconciler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// RetryingCondition is set while the reconcile of a resource is retried with exponential backoff.
+	// Its message holds the attempt count and the requeue interval
+	RetryingCondition apis.ConditionType = "Retrying"
+	// BackingOffReason is the reason of the Retrying condition while retries are ongoing
+	BackingOffReason apis.ConditionReason = "BackingOff"
+	// RetrySucceededReason is the reason of the Retrying condition once a reconcile succeeds
+	RetrySucceededReason apis.ConditionReason = "Succeeded"
+)
+
+// DefaultRetryBackoff is the exponential backoff used for the errors returned by NewBackoffError:
+// starting at one second, doubling at every attempt with 10% jitter, up to 10 minutes
+var DefaultRetryBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Cap:      10 * time.Minute,
+}
+
+// defaultRetryBackoffTTL is how long the attempts of an object are remembered after its last failure,
+// when the backoff has no cap
+const defaultRetryBackoffTTL = time.Hour
+
+type retryAttempts struct {
+	count int
+	last  time.Time
+}
+
+// retryBackoff counts the consecutive failed attempts of each object. The attempts of objects not failing
+// anymore without reaching ManageSuccess, e.g. deleted ones, are forgotten after twice the cap of the backoff
+type retryBackoff struct {
+	backoff wait.Backoff
+
+	mu       sync.Mutex
+	attempts map[types.UID]*retryAttempts
+	lastGC   time.Time
+}
+
+func newRetryBackoff(backoff wait.Backoff) *retryBackoff {
+	return &retryBackoff{
+		backoff:  backoff,
+		attempts: map[types.UID]*retryAttempts{},
+	}
+}
+
+// ttl returns how long the attempts of an object are remembered after its last failure
+func (b *retryBackoff) ttl() time.Duration {
+	if b.backoff.Cap > 0 {
+		return 2 * b.backoff.Cap
+	}
+	return defaultRetryBackoffTTL
+}
+
+// gc forgets, at most once per ttl, the attempts of the objects which did not fail for longer than the ttl
+func (b *retryBackoff) gc(now time.Time) {
+	ttl := b.ttl()
+	if now.Sub(b.lastGC) < ttl {
+		return
+	}
+	b.lastGC = now
+	for uid, attempts := range b.attempts {
+		if now.Sub(attempts.last) >= ttl {
+			delete(b.attempts, uid)
+		}
+	}
+}
+
+// next records a failed attempt for the object with the given uid and returns the attempt count and
+// the interval to wait before the next one
+func (b *retryBackoff) next(uid types.UID) (int, time.Duration) {
+	b.mu.Lock()
+	now := time.Now()
+	b.gc(now)
+	attempts, ok := b.attempts[uid]
+	if !ok {
+		attempts = &retryAttempts{}
+		b.attempts[uid] = attempts
+	}
+	attempts.count++
+	attempts.last = now
+	attempt := attempts.count
+	b.mu.Unlock()
+
+	interval := float64(b.backoff.Duration)
+	for i := 1; i < attempt && (b.backoff.Cap <= 0 || interval < float64(b.backoff.Cap)); i++ {
+		interval *= b.backoff.Factor
+	}
+	retryAfter := time.Duration(interval)
+	if b.backoff.Jitter > 0 {
+		retryAfter = wait.Jitter(retryAfter, b.backoff.Jitter)
+	}
+	if b.backoff.Cap > 0 && retryAfter > b.backoff.Cap {
+		retryAfter = b.backoff.Cap
+	}
+	return attempt, retryAfter
+}
+
+// reset forgets the failed attempts of the object with the given uid
+func (b *retryBackoff) reset(uid types.UID) {
+	b.mu.Lock()
+	delete(b.attempts, uid)
+	b.mu.Unlock()
+}
+
+// retryingCondition returns the Retrying condition reporting the given attempt
+func retryingCondition(attempt int, retryAfter time.Duration, issue error) apis.Condition {
+	return apis.Condition{
+		Type:    RetryingCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  BackingOffReason,
+		Message: fmt.Sprintf("retry %d, next in %s: %s", attempt, retryAfter.Round(time.Second), issue.Error()),
+	}
+}
+
+// resetBackoff clears the backoff state of obj, marking its Retrying condition as succeeded if present
+func (r *Reconciler) resetBackoff(obj Resource) {
+	r.retries.reset(obj.GetUID())
+
+	getter, ok := obj.(conditionsGetter)
+	if !ok {
+		return
+	}
+	if c := getter.GetConditions().GetCondition(RetryingCondition); c == nil || !c.IsTrue() {
+		return
+	}
+	if conditionsStatusAware, ok := obj.(conditionsStatusAware); ok {
+		conditionsStatusAware.SetCondition(apis.Condition{
+			Type:   RetryingCondition,
+			Status: corev1.ConditionFalse,
+			Reason: RetrySucceededReason,
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	b := newRetryBackoff(wait.Backoff{Duration: time.Second, Factor: 2, Cap: 10 * time.Second})
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, wantAfter := range want {
+		attempt, retryAfter := b.next("a")
+		if attempt != i+1 || retryAfter != wantAfter {
+			t.Errorf("next() = %d, %s, want %d, %s", attempt, retryAfter, i+1, wantAfter)
+		}
+	}
+
+	b.reset("a")
+	if attempt, _ := b.next("a"); attempt != 1 {
+		t.Errorf("attempt after reset = %d, want 1", attempt)
+	}
+}
+
+func TestRetryBackoffGC(t *testing.T) {
+	b := newRetryBackoff(wait.Backoff{Duration: time.Second, Factor: 2, Cap: time.Minute})
+	b.next("stale")
+	b.next("active")
+
+	// the stale object stopped failing, e.g. because it was deleted
+	b.attempts["stale"].last = time.Now().Add(-3 * time.Minute)
+	b.lastGC = time.Now().Add(-3 * time.Minute)
+	b.next("active")
+
+	if _, ok := b.attempts["stale"]; ok {
+		t.Error("stale attempts not forgotten")
+	}
+	if attempts, ok := b.attempts["active"]; !ok || attempts.count != 2 {
+		t.Errorf("active attempts = %+v, want 2", attempts)
+	}
+}
@@ -0,0 +1,97 @@
+package reconciler
+
+import (
+	"errors"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultAPIRetryAfter is the requeue interval of retriable API errors without a Retry-After suggestion
+const defaultAPIRetryAfter = 5 * time.Second
+
+// ErrorClass is how ManageError handles an error
+type ErrorClass string
+
+const (
+	// ErrorClassDefault returns the error, requeueing through the rate limiter of the controller
+	ErrorClassDefault ErrorClass = ""
+	// ErrorClassRequeue requeues immediately without reporting the error
+	ErrorClassRequeue ErrorClass = "Requeue"
+	// ErrorClassRetriable requeues after the interval suggested by the error
+	ErrorClassRetriable ErrorClass = "Retriable"
+	// ErrorClassBackoff requeues after an interval growing exponentially with the consecutive failures of the object
+	ErrorClassBackoff ErrorClass = "Backoff"
+	// ErrorClassNotRetriable requeues after a long interval, as the error is not expected to go away by itself
+	ErrorClassNotRetriable ErrorClass = "NotRetriable"
+	// ErrorClassTerminal never requeues and sets the Stalled condition until the generation of the object changes
+	ErrorClassTerminal ErrorClass = "Terminal"
+)
+
+// DefaultErrorClasses returns the classes of the Kubernetes API errors, by reason, used by ManageError
+// unless overridden with WithErrorClass
+func DefaultErrorClasses() map[metav1.StatusReason]ErrorClass {
+	return map[metav1.StatusReason]ErrorClass{
+		metav1.StatusReasonConflict:        ErrorClassRequeue,
+		metav1.StatusReasonAlreadyExists:   ErrorClassRequeue,
+		metav1.StatusReasonForbidden:       ErrorClassNotRetriable,
+		metav1.StatusReasonInvalid:         ErrorClassNotRetriable,
+		metav1.StatusReasonBadRequest:      ErrorClassNotRetriable,
+		metav1.StatusReasonTimeout:         ErrorClassRetriable,
+		metav1.StatusReasonServerTimeout:   ErrorClassRetriable,
+		metav1.StatusReasonTooManyRequests: ErrorClassRetriable,
+	}
+}
+
+// classifyError returns the class of err and, for retriable errors, the interval after which to retry.
+// The toolkit errors are classified by type, Kubernetes API errors by reason
+func (r *Reconciler) classifyError(err error) (ErrorClass, time.Duration) {
+	if agg, ok := asAggregate(err); ok {
+		return classifyAggregate(agg, r.classifyError)
+	}
+	if class, retryAfter := toolkitErrorClass(err); class != ErrorClassDefault {
+		return class, retryAfter
+	}
+
+	var apiStatus apierrors.APIStatus
+	if !errors.As(err, &apiStatus) {
+		return ErrorClassDefault, 0
+	}
+	status := apiStatus.Status()
+	class := r.errorClasses[status.Reason]
+	if class != ErrorClassRetriable {
+		return class, 0
+	}
+	if status.Details != nil && status.Details.RetryAfterSeconds > 0 {
+		return class, time.Duration(status.Details.RetryAfterSeconds) * time.Second
+	}
+	return class, defaultAPIRetryAfter
+}
+
+// toolkitErrorClass classifies the toolkit errors by type, other errors are ErrorClassDefault
+func toolkitErrorClass(err error) (ErrorClass, time.Duration) {
+	if agg, ok := asAggregate(err); ok {
+		return classifyAggregate(agg, toolkitErrorClass)
+	}
+	var terminalErr *terminalError
+	if errors.As(err, &terminalErr) {
+		return ErrorClassTerminal, 0
+	}
+	var notRetriableErr *notRetriableError
+	if errors.As(err, &notRetriableErr) {
+		return ErrorClassNotRetriable, 0
+	}
+	var retriableErr *retriableError
+	if errors.As(err, &retriableErr) {
+		if retriableErr.backoff {
+			return ErrorClassBackoff, 0
+		}
+		return ErrorClassRetriable, retriableErr.retryAfter
+	}
+	var conflictErr *ConflictError
+	if errors.As(err, &conflictErr) {
+		return ErrorClassRequeue, 0
+	}
+	return ErrorClassDefault, 0
+}
@@ -0,0 +1,124 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestManageErrorClassification(t *testing.T) {
+	gr := corev1.Resource("configmaps")
+	tests := []struct {
+		name       string
+		err        error
+		wantResult reconcile.Result
+		wantErr    bool
+		wantEvent  string
+	}{
+		{
+			name:       "conflict",
+			err:        apierrors.NewConflict(gr, "cm", errors.New("modified")),
+			wantResult: reconcile.Result{Requeue: true},
+		},
+		{
+			name:       "already exists",
+			err:        apierrors.NewAlreadyExists(gr, "cm"),
+			wantResult: reconcile.Result{Requeue: true},
+		},
+		{
+			name:       "forbidden",
+			err:        apierrors.NewForbidden(gr, "cm", errors.New("denied")),
+			wantResult: reconcile.Result{RequeueAfter: 6 * time.Hour},
+			wantEvent:  string(metav1.StatusReasonForbidden),
+		},
+		{
+			name:       "too many requests",
+			err:        apierrors.NewTooManyRequests("slow down", 7),
+			wantResult: reconcile.Result{RequeueAfter: 7 * time.Second},
+			wantEvent:  string(metav1.StatusReasonTooManyRequests),
+		},
+		{
+			name:       "retriable",
+			err:        NewRetriableError(time.Minute, errors.New("later")),
+			wantResult: reconcile.Result{RequeueAfter: time.Minute},
+			wantEvent:  ProcessingErrorReason,
+		},
+		{
+			name:       "other",
+			err:        errors.New("failed"),
+			wantResult: reconcile.Result{Requeue: true},
+			wantErr:    true,
+			wantEvent:  ProcessingErrorReason,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns", UID: "uid"}}
+			recorder := record.NewFakeRecorder(10)
+			r := New(fake.NewFakeClientWithScheme(scheme.Scheme, obj.DeepCopy()), scheme.Scheme, recorder, "test")
+
+			result, err := r.ManageError(context.Background(), obj, tt.err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ManageError() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.wantResult {
+				t.Errorf("ManageError() = %+v, want %+v", result, tt.wantResult)
+			}
+
+			var event string
+			select {
+			case event = <-recorder.Events:
+			default:
+			}
+			if tt.wantEvent == "" && event != "" {
+				t.Errorf("unexpected event %q", event)
+			} else if tt.wantEvent != "" && event != "Warning "+tt.wantEvent+" "+tt.err.Error() {
+				t.Errorf("event = %q, want reason %q", event, tt.wantEvent)
+			}
+		})
+	}
+}
+
+// readyObject is a resource with a ready status
+type readyObject struct {
+	corev1.ConfigMap
+	status apis.ReadyStatus
+}
+
+func (o *readyObject) GetReadyStatus() apis.ReadyStatus {
+	return o.status
+}
+
+func (o *readyObject) SetReadyStatus(status apis.ReadyStatus) {
+	o.status = status
+}
+
+func TestManageErrorRequeueKeepsStatus(t *testing.T) {
+	obj := &readyObject{
+		ConfigMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns", UID: "uid"}},
+		status:    apis.ReadyStatusOK(),
+	}
+	// the status is not written, no client is needed
+	r := New(nil, scheme.Scheme, record.NewFakeRecorder(10), "test")
+
+	result, err := r.ManageError(context.Background(), obj, apierrors.NewConflict(corev1.Resource("configmaps"), "cm", errors.New("modified")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (reconcile.Result{Requeue: true}) {
+		t.Errorf("ManageError() = %+v, want an immediate requeue", result)
+	}
+	if !obj.status.Ready {
+		t.Errorf("ready status = %+v, want unchanged", obj.status)
+	}
+}
@@ -0,0 +1,225 @@
+package reconciler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ChangeDetection is the strategy used by CreateOrUpdateResource to decide whether an update is required
+type ChangeDetection string
+
+const (
+	// ChangeDetectionSemantic compares the desired object with the found one, ignoring server managed metadata,
+	// status and fields not set in the desired object (usually defaulted by the API server). The maps fully owned
+	// by the operator, such as the data of ConfigMaps, are compared both ways. Fields removed from the desired
+	// object or reset to their zero value are detected by comparing its hash with the LastAppliedHashAnnotation
+	// of the found object, which is maintained as with ChangeDetectionHash
+	ChangeDetectionSemantic ChangeDetection = "Semantic"
+	// ChangeDetectionHash compares the hash of the desired object with the one stored in the
+	// LastAppliedHashAnnotation of the found object. Useful for kinds where defaulting makes the
+	// semantic comparison unreliable
+	ChangeDetectionHash ChangeDetection = "Hash"
+	// ChangeDetectionNone always updates the found object
+	ChangeDetectionNone ChangeDetection = "None"
+)
+
+// LastAppliedHashAnnotation stores the hash of the last applied desired state when ChangeDetectionHash is used
+const LastAppliedHashAnnotation = "toolkit/last-applied-hash"
+
+// serverManagedMetadata lists metadata fields populated by the API server
+var serverManagedMetadata = []string{
+	"uid",
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"deletionTimestamp",
+	"deletionGracePeriodSeconds",
+	"selfLink",
+	"managedFields",
+}
+
+// ownedFields lists the top level maps fully owned by the operator: keys found but not desired are differences
+var ownedFields = []string{"data", "binaryData", "stringData"}
+
+// changeDetectionFor returns the change detection strategy configured for the kind of obj
+func (r *Reconciler) changeDetectionFor(obj runtime.Object) ChangeDetection {
+	if gvk, err := apiutil.GVKForObject(obj, r.scheme); err == nil {
+		if mode, ok := r.kindChangeDetection[gvk]; ok {
+			return mode
+		}
+	}
+	return r.changeDetection
+}
+
+// needsUpdate returns whether found has to be updated to match desired.
+// The hash annotation of desired must have been set with setLastAppliedHash before copying the preserved fields,
+// so that the values owned by other actors don't make the hash change
+func (r *Reconciler) needsUpdate(desired Resource, found runtime.Object) (bool, error) {
+	mode := r.changeDetectionFor(desired)
+	if mode == ChangeDetectionNone {
+		return true, nil
+	}
+
+	hash := desired.GetAnnotations()[LastAppliedHashAnnotation]
+	u, err := toUnstructured(found)
+	if err != nil {
+		return true, err
+	}
+	foundAnnotations, _, _ := unstructured.NestedStringMap(u, "metadata", "annotations")
+	if foundAnnotations[LastAppliedHashAnnotation] != hash {
+		return true, nil
+	}
+
+	if mode == ChangeDetectionSemantic {
+		equal, err := semanticallyEqual(desired, found)
+		return !equal, err
+	}
+	return false, nil
+}
+
+// setLastAppliedHash sets the hash annotation on desired, unless change detection is disabled for its kind.
+// It returns the hash
+func (r *Reconciler) setLastAppliedHash(desired Resource) (string, error) {
+	if r.changeDetectionFor(desired) == ChangeDetectionNone {
+		return "", nil
+	}
+	hash, err := hashObject(desired)
+	if err != nil {
+		return "", err
+	}
+	annotations := desired.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[LastAppliedHashAnnotation] = hash
+	desired.SetAnnotations(annotations)
+	return hash, nil
+}
+
+// semanticallyEqual returns whether every meaningful field set in desired has the same value in found,
+// and the owned fields of found have no keys missing from desired
+func semanticallyEqual(desired, found runtime.Object) (bool, error) {
+	d, err := toUnstructured(desired)
+	if err != nil {
+		return false, err
+	}
+	f, err := toUnstructured(found)
+	if err != nil {
+		return false, err
+	}
+	normalize(d)
+	normalize(f)
+	for _, field := range ownedFields {
+		if fv, ok := f[field]; ok && !isSubset(fv, d[field]) {
+			return false, nil
+		}
+	}
+	return isSubset(d, f), nil
+}
+
+// hashObject returns the hash of the meaningful fields of obj
+func hashObject(obj runtime.Object) (string, error) {
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return "", err
+	}
+	normalize(u)
+	data, err := json.Marshal(u)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// toUnstructured returns a copy of obj as a generic map
+func toUnstructured(obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return runtime.DeepCopyJSON(u.UnstructuredContent()), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// normalize drops the fields not relevant for comparison: type information, server managed metadata,
+// the hash annotation and the status
+func normalize(u map[string]interface{}) {
+	delete(u, "apiVersion")
+	delete(u, "kind")
+	delete(u, "status")
+	metadata, ok := u["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, field := range serverManagedMetadata {
+		delete(metadata, field)
+	}
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		delete(annotations, LastAppliedHashAnnotation)
+	}
+}
+
+// isSubset returns whether every value set in desired has the same value in found.
+// Values absent from found match empty desired values
+func isSubset(desired, found interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		f, ok := found.(map[string]interface{})
+		if !ok {
+			return isEmpty(d) && isEmpty(found)
+		}
+		for k, v := range d {
+			fv, ok := f[k]
+			if !ok {
+				if !isEmpty(v) {
+					return false
+				}
+				continue
+			}
+			if !isSubset(v, fv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		f, ok := found.([]interface{})
+		if !ok {
+			return isEmpty(d) && isEmpty(found)
+		}
+		if len(d) != len(f) {
+			return false
+		}
+		for i := range d {
+			if !isSubset(d[i], f[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return valuesEqual(desired, found)
+}
+
+// isEmpty returns whether v is a zero JSON value
+func isEmpty(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(t) == 0
+	case []interface{}:
+		return len(t) == 0
+	case string:
+		return t == ""
+	case bool:
+		return !t
+	case int64:
+		return t == 0
+	case float64:
+		return t == 0
+	}
+	return false
+}
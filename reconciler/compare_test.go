@@ -0,0 +1,209 @@
+package reconciler
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestIsSubset(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired interface{}
+		found   interface{}
+		want    bool
+	}{
+		{
+			name:    "equal scalars",
+			desired: "a",
+			found:   "a",
+			want:    true,
+		},
+		{
+			name:    "different scalars",
+			desired: "a",
+			found:   "b",
+			want:    false,
+		},
+		{
+			name:    "integer and float",
+			desired: int64(1),
+			found:   float64(1),
+			want:    true,
+		},
+		{
+			name:    "field defaulted in found",
+			desired: map[string]interface{}{"a": "x"},
+			found:   map[string]interface{}{"a": "x", "b": "default"},
+			want:    true,
+		},
+		{
+			name:    "field missing in found",
+			desired: map[string]interface{}{"a": "x", "b": "y"},
+			found:   map[string]interface{}{"a": "x"},
+			want:    false,
+		},
+		{
+			name:    "empty field missing in found",
+			desired: map[string]interface{}{"a": "x", "b": ""},
+			found:   map[string]interface{}{"a": "x"},
+			want:    true,
+		},
+		{
+			name:    "nested difference",
+			desired: map[string]interface{}{"a": map[string]interface{}{"b": "x"}},
+			found:   map[string]interface{}{"a": map[string]interface{}{"b": "y"}},
+			want:    false,
+		},
+		{
+			name:    "empty map and missing map",
+			desired: map[string]interface{}{},
+			found:   nil,
+			want:    true,
+		},
+		{
+			name:    "non empty map and missing map",
+			desired: map[string]interface{}{"a": "x"},
+			found:   nil,
+			want:    false,
+		},
+		{
+			name:    "lists of different length",
+			desired: []interface{}{"a"},
+			found:   []interface{}{"a", "b"},
+			want:    false,
+		},
+		{
+			name:    "lists with defaulted items",
+			desired: []interface{}{map[string]interface{}{"name": "a"}},
+			found:   []interface{}{map[string]interface{}{"name": "a", "protocol": "TCP"}},
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSubset(tt.desired, tt.found); got != tt.want {
+				t.Errorf("isSubset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsUpdate(t *testing.T) {
+	configMap := func(data map[string]string, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns", Labels: labels},
+			Data:       data,
+		}
+	}
+	deployment := func(paused bool) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "ns"},
+			Spec:       appsv1.DeploymentSpec{Paused: paused},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		applied Resource
+		mutate  func(found Resource)
+		desired Resource
+		want    bool
+	}{
+		{
+			name:    "unchanged",
+			applied: configMap(map[string]string{"a": "1"}, nil),
+			desired: configMap(map[string]string{"a": "1"}, nil),
+			want:    false,
+		},
+		{
+			name:    "data key removed",
+			applied: configMap(map[string]string{"a": "1", "b": "2"}, nil),
+			desired: configMap(map[string]string{"a": "1"}, nil),
+			want:    true,
+		},
+		{
+			name:    "data key added to found",
+			applied: configMap(map[string]string{"a": "1"}, nil),
+			mutate: func(found Resource) {
+				found.(*corev1.ConfigMap).Data["b"] = "2"
+			},
+			desired: configMap(map[string]string{"a": "1"}, nil),
+			want:    true,
+		},
+		{
+			name:    "data value changed in found",
+			applied: configMap(map[string]string{"a": "1"}, nil),
+			mutate: func(found Resource) {
+				found.(*corev1.ConfigMap).Data["a"] = "2"
+			},
+			desired: configMap(map[string]string{"a": "1"}, nil),
+			want:    true,
+		},
+		{
+			name:    "label removed",
+			applied: configMap(nil, map[string]string{"a": "1", "b": "2"}),
+			desired: configMap(nil, map[string]string{"a": "1"}),
+			want:    true,
+		},
+		{
+			name:    "foreign label added to found",
+			applied: configMap(nil, map[string]string{"a": "1"}),
+			mutate: func(found Resource) {
+				found.SetLabels(map[string]string{"a": "1", "other": "x"})
+			},
+			desired: configMap(nil, map[string]string{"a": "1"}),
+			want:    false,
+		},
+		{
+			name:    "field reset to zero value",
+			applied: deployment(true),
+			desired: deployment(false),
+			want:    true,
+		},
+		{
+			name:    "field defaulted by the server",
+			applied: deployment(false),
+			mutate: func(found Resource) {
+				limit := int32(10)
+				found.(*appsv1.Deployment).Spec.RevisionHistoryLimit = &limit
+			},
+			desired: deployment(false),
+			want:    false,
+		},
+		{
+			name:    "found without hash",
+			applied: configMap(map[string]string{"a": "1"}, nil),
+			mutate: func(found Resource) {
+				found.SetAnnotations(nil)
+			},
+			desired: configMap(map[string]string{"a": "1"}, nil),
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{scheme: scheme.Scheme, changeDetection: ChangeDetectionSemantic}
+			if _, err := r.setLastAppliedHash(tt.applied); err != nil {
+				t.Fatal(err)
+			}
+			found := tt.applied.DeepCopyObject().(Resource)
+			if tt.mutate != nil {
+				tt.mutate(found)
+			}
+			if _, err := r.setLastAppliedHash(tt.desired); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.needsUpdate(tt.desired, found)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("needsUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
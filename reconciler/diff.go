@@ -0,0 +1,175 @@
+package reconciler
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DiffAnnotation is the event annotation holding the JSON encoded Diff, when diff events are enabled
+const DiffAnnotation = "toolkit/diff"
+
+// redacted replaces the values of secret data in diffs
+const redacted = "<redacted>"
+
+// ChangeType is the kind of change of a field, named after the JSON patch operations
+type ChangeType string
+
+const (
+	// ChangeAdd means the field has been added
+	ChangeAdd ChangeType = "add"
+	// ChangeRemove means the field has been removed
+	ChangeRemove ChangeType = "remove"
+	// ChangeReplace means the value of the field has changed
+	ChangeReplace ChangeType = "replace"
+)
+
+// FieldChange is the change of a single field, identified by its JSON pointer path
+type FieldChange struct {
+	Type ChangeType  `json:"op"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Diff is a list of field changes between two versions of an object
+type Diff []FieldChange
+
+// String returns the JSON representation of the diff
+func (d Diff) String() string {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(d); err != nil {
+		return err.Error()
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// ComputeDiff returns the field level changes from one object to another, ignoring type information,
+// server managed metadata and status. Lists of different lengths are reported as a whole.
+// Secret data is redacted
+func ComputeDiff(from, to runtime.Object) (Diff, error) {
+	diff, err := computeDiff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if isSecret(from) || isSecret(to) {
+		diff.redactSecretData()
+	}
+	return diff, nil
+}
+
+// computeDiff operates as ComputeDiff, without redacting secrets
+func computeDiff(from, to runtime.Object) (Diff, error) {
+	f, err := toUnstructured(from)
+	if err != nil {
+		return nil, err
+	}
+	t, err := toUnstructured(to)
+	if err != nil {
+		return nil, err
+	}
+	normalize(f)
+	normalize(t)
+
+	diff := Diff{}
+	diffValues("", f, t, &diff)
+	return diff, nil
+}
+
+func diffValues(path string, from, to interface{}, diff *Diff) {
+	switch f := from.(type) {
+	case map[string]interface{}:
+		t, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(f)+len(t))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		for k := range t {
+			if _, ok := f[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fv, inFrom := f[k]
+			tv, inTo := t[k]
+			p := path + "/" + escapePointer(k)
+			switch {
+			case !inFrom:
+				*diff = append(*diff, FieldChange{Type: ChangeAdd, Path: p, New: tv})
+			case !inTo:
+				*diff = append(*diff, FieldChange{Type: ChangeRemove, Path: p, Old: fv})
+			default:
+				diffValues(p, fv, tv, diff)
+			}
+		}
+		return
+	case []interface{}:
+		t, ok := to.([]interface{})
+		if !ok || len(f) != len(t) {
+			break
+		}
+		for i := range f {
+			diffValues(path+"/"+strconv.Itoa(i), f[i], t[i], diff)
+		}
+		return
+	}
+	if !valuesEqual(from, to) {
+		*diff = append(*diff, FieldChange{Type: ChangeReplace, Path: path, Old: from, New: to})
+	}
+}
+
+// valuesEqual compares JSON values, regardless of the representation of numbers
+func valuesEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case int64:
+		if bv, ok := b.(float64); ok {
+			return float64(av) == bv
+		}
+	case float64:
+		if bv, ok := b.(int64); ok {
+			return av == float64(bv)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// escapePointer escapes a JSON pointer reference token as defined by RFC 6901
+func escapePointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// isSecret returns whether obj is a core Secret, typed or unstructured
+func isSecret(obj runtime.Object) bool {
+	if _, ok := obj.(*corev1.Secret); ok {
+		return true
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Secret"
+}
+
+// redactSecretData hides the values of the data and stringData fields
+func (d Diff) redactSecretData() {
+	for i, c := range d {
+		if !strings.HasPrefix(c.Path, "/data") && !strings.HasPrefix(c.Path, "/stringData") {
+			continue
+		}
+		if c.Old != nil {
+			d[i].Old = redacted
+		}
+		if c.New != nil {
+			d[i].New = redacted
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package reconciler
 
 import (
+	"errors"
 	"time"
 
 	"github.com/6RiverSystems/operator-toolkit/apis"
@@ -18,6 +19,11 @@ func (e *ConditionError) Error() string {
 	return e.Err.Error()
 }
 
+// Unwrap returns the wrapped error
+func (e *ConditionError) Unwrap() error {
+	return e.Err
+}
+
 // Condition converts consdition error into condition structure
 func (e *ConditionError) Condition() apis.Condition {
 	return apis.Condition{
@@ -54,6 +60,10 @@ func (e *notRetriableError) Error() string {
 	return e.issue.Error()
 }
 
+func (e *notRetriableError) Unwrap() error {
+	return e.issue
+}
+
 // NewNotRetriableError returns notretriable error
 func NewNotRetriableError(issue error) error {
 	return &notRetriableError{issue}
@@ -62,12 +72,17 @@ func NewNotRetriableError(issue error) error {
 type retriableError struct {
 	issue      error
 	retryAfter time.Duration
+	backoff    bool
 }
 
 func (e *retriableError) Error() string {
 	return e.issue.Error()
 }
 
+func (e *retriableError) Unwrap() error {
+	return e.issue
+}
+
 // NewRetriableError returns retriable error
 func NewRetriableError(retryAfter time.Duration, issue error) error {
 	return &retriableError{
@@ -75,3 +90,142 @@ func NewRetriableError(retryAfter time.Duration, issue error) error {
 		issue:      issue,
 	}
 }
+
+// NewBackoffError returns retriable error requeued with an exponential backoff tracked per object,
+// see WithRetryBackoff. The backoff is reset by ManageSuccess
+func NewBackoffError(issue error) error {
+	return &retriableError{
+		issue:   issue,
+		backoff: true,
+	}
+}
+
+type terminalError struct {
+	issue error
+}
+
+func (e *terminalError) Error() string {
+	return e.issue.Error()
+}
+
+func (e *terminalError) Unwrap() error {
+	return e.issue
+}
+
+// NewTerminalError returns terminal error: the reconcile is not requeued and the resource is marked
+// with the Stalled condition until its generation changes
+func NewTerminalError(issue error) error {
+	return &terminalError{issue}
+}
+
+// NewRetriableConditionError makes new condition error with provided reason, retried after retryAfter
+func NewRetriableConditionError(
+	ctype apis.ConditionType,
+	reason apis.ConditionReason,
+	retryAfter time.Duration,
+	issue error,
+) error {
+	return NewConditionErrorWithReason(ctype, reason, NewRetriableError(retryAfter, issue))
+}
+
+// NewBackoffConditionError makes new condition error with provided reason, retried with backoff
+func NewBackoffConditionError(
+	ctype apis.ConditionType,
+	reason apis.ConditionReason,
+	issue error,
+) error {
+	return NewConditionErrorWithReason(ctype, reason, NewBackoffError(issue))
+}
+
+// NewNotRetriableConditionError makes new not retriable condition error with provided reason
+func NewNotRetriableConditionError(
+	ctype apis.ConditionType,
+	reason apis.ConditionReason,
+	issue error,
+) error {
+	return NewConditionErrorWithReason(ctype, reason, NewNotRetriableError(issue))
+}
+
+// NewTerminalConditionError makes new terminal condition error with provided reason
+func NewTerminalConditionError(
+	ctype apis.ConditionType,
+	reason apis.ConditionReason,
+	issue error,
+) error {
+	return NewConditionErrorWithReason(ctype, reason, NewTerminalError(issue))
+}
+
+// IsRetriable returns whether err or an error it wraps was made by NewRetriableError or NewBackoffError.
+// An AggregateError is retriable if its most urgent error is, see AggregateError
+func IsRetriable(err error) bool {
+	if agg, ok := asAggregate(err); ok {
+		class, _ := classifyAggregate(agg, toolkitErrorClass)
+		return class == ErrorClassRetriable || class == ErrorClassBackoff
+	}
+	var retriableErr *retriableError
+	return errors.As(err, &retriableErr)
+}
+
+// IsNotRetriable returns whether err or an error it wraps was made by NewNotRetriableError.
+// An AggregateError is not retriable if its most urgent error is not
+func IsNotRetriable(err error) bool {
+	if agg, ok := asAggregate(err); ok {
+		class, _ := classifyAggregate(agg, toolkitErrorClass)
+		return class == ErrorClassNotRetriable
+	}
+	var notRetriableErr *notRetriableError
+	return errors.As(err, &notRetriableErr)
+}
+
+// IsTerminal returns whether err or an error it wraps was made by NewTerminalError.
+// An AggregateError is terminal if all its errors are
+func IsTerminal(err error) bool {
+	if agg, ok := asAggregate(err); ok {
+		class, _ := classifyAggregate(agg, toolkitErrorClass)
+		return class == ErrorClassTerminal
+	}
+	var terminalErr *terminalError
+	return errors.As(err, &terminalErr)
+}
+
+// RetryAfter returns the interval after which a retriable err should be retried, zero for errors retried
+// with backoff and the shortest one of the retriable errors of an AggregateError. The boolean is false if
+// err is not retriable
+func RetryAfter(err error) (time.Duration, bool) {
+	if agg, ok := asAggregate(err); ok {
+		switch class, retryAfter := classifyAggregate(agg, toolkitErrorClass); class {
+		case ErrorClassRetriable, ErrorClassBackoff:
+			return retryAfter, true
+		}
+		return 0, false
+	}
+	var retriableErr *retriableError
+	if !errors.As(err, &retriableErr) {
+		return 0, false
+	}
+	return retriableErr.retryAfter, true
+}
+
+// AsConditionError returns the first ConditionError in the chain of err, if any, or the first one
+// of the errors of an AggregateError
+func AsConditionError(err error) (*ConditionError, bool) {
+	conditionErrs := conditionErrors(err)
+	if len(conditionErrs) == 0 {
+		return nil, false
+	}
+	return conditionErrs[0], true
+}
+
+// ConflictError is returned when a write keeps failing with Conflict after all the retries
+type ConflictError struct {
+	Err error
+}
+
+func (e *ConflictError) Error() string {
+	return "retries exhausted: " + e.Err.Error()
+}
+
+// Unwrap returns the last conflict error
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,137 @@
+package reconciler
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// ProcessingErrorReason is the reason of the events recorded by ManageError for errors without a more specific one
+const ProcessingErrorReason = "ProcessingError"
+
+type eventKey struct {
+	uid     types.UID
+	reason  string
+	message string
+}
+
+type limiterKey struct {
+	uid    types.UID
+	reason string
+}
+
+type limiterEntry struct {
+	limiter  flowcontrol.RateLimiter
+	lastUsed time.Time
+}
+
+// eventLimiter drops the events already recorded for the same object within the deduplication window and
+// rate limits the events of each object and reason
+type eventLimiter struct {
+	window time.Duration
+	qps    float32
+	burst  int
+
+	mu       sync.Mutex
+	seen     map[eventKey]time.Time
+	limiters map[limiterKey]*limiterEntry
+	lastGC   time.Time
+}
+
+func newEventLimiter(window time.Duration, qps float32, burst int) *eventLimiter {
+	return &eventLimiter{
+		window:   window,
+		qps:      qps,
+		burst:    burst,
+		seen:     map[eventKey]time.Time{},
+		limiters: map[limiterKey]*limiterEntry{},
+	}
+}
+
+// allow returns whether the event with the given reason and message can be recorded for the object with the given uid
+func (l *eventLimiter) allow(uid types.UID, reason, message string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.gc(now)
+
+	key := eventKey{uid: uid, reason: reason, message: message}
+	if last, ok := l.seen[key]; ok && l.window > 0 && now.Sub(last) < l.window {
+		return false
+	}
+	if l.qps > 0 {
+		lkey := limiterKey{uid: uid, reason: reason}
+		entry, ok := l.limiters[lkey]
+		if !ok {
+			entry = &limiterEntry{limiter: flowcontrol.NewTokenBucketRateLimiter(l.qps, l.burst)}
+			l.limiters[lkey] = entry
+		}
+		entry.lastUsed = now
+		if !entry.limiter.TryAccept() {
+			return false
+		}
+	}
+	if l.window > 0 {
+		l.seen[key] = now
+	}
+	return true
+}
+
+// gc forgets, at most once per window, the events and limiters unused for longer than the window or
+// than the time needed to refill the rate limiters
+func (l *eventLimiter) gc(now time.Time) {
+	ttl := l.window
+	if l.qps > 0 {
+		if refill := time.Duration(float64(l.burst) / float64(l.qps) * float64(time.Second)); refill > ttl {
+			ttl = refill
+		}
+	}
+	if ttl <= 0 || now.Sub(l.lastGC) < ttl {
+		return
+	}
+	l.lastGC = now
+	for key, last := range l.seen {
+		if now.Sub(last) >= l.window {
+			delete(l.seen, key)
+		}
+	}
+	for key, entry := range l.limiters {
+		if now.Sub(entry.lastUsed) >= ttl {
+			entry.limiter.Stop()
+			delete(l.limiters, key)
+		}
+	}
+}
+
+// errorEventReason returns the event reason for err: the reason of its (first) ConditionError, the reason of the
+// Kubernetes API error it wraps or ProcessingErrorReason
+func errorEventReason(err error) string {
+	if conditionErr, ok := AsConditionError(err); ok && conditionErr.Reason != "" {
+		return string(conditionErr.Reason)
+	}
+	var apiStatus apierrors.APIStatus
+	if errors.As(err, &apiStatus) && apiStatus.Status().Reason != "" {
+		return string(apiStatus.Status().Reason)
+	}
+	return ProcessingErrorReason
+}
+
+// recordEvent records an event on obj, unless it is a duplicate or rate limited
+func (r *Reconciler) recordEvent(obj Resource, eventtype, reason, message string) {
+	if !r.events.allow(obj.GetUID(), reason, message) {
+		r.loggerFor(obj).V(2).Info("Event suppressed", "reason", reason)
+		return
+	}
+	r.recorder.Event(obj, eventtype, reason, message)
+}
+
+// recordError records a Warning event for err on obj, unless it is a duplicate or rate limited
+func (r *Reconciler) recordError(obj Resource, err error) {
+	r.recordEvent(obj, corev1.EventTypeWarning, errorEventReason(err), err.Error())
+}
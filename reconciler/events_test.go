@@ -0,0 +1,122 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEventLimiter(t *testing.T) {
+	type event struct {
+		uid     types.UID
+		reason  string
+		message string
+		want    bool
+	}
+	tests := []struct {
+		name   string
+		window time.Duration
+		qps    float32
+		burst  int
+		events []event
+	}{
+		{
+			name:   "duplicates within the window",
+			window: time.Hour,
+			events: []event{
+				{uid: "a", reason: "Failed", message: "boom", want: true},
+				{uid: "a", reason: "Failed", message: "boom", want: false},
+				{uid: "a", reason: "Failed", message: "other", want: true},
+				{uid: "a", reason: "Other", message: "boom", want: true},
+				{uid: "b", reason: "Failed", message: "boom", want: true},
+			},
+		},
+		{
+			name: "no deduplication",
+			events: []event{
+				{uid: "a", reason: "Failed", message: "boom", want: true},
+				{uid: "a", reason: "Failed", message: "boom", want: true},
+			},
+		},
+		{
+			name:  "rate limited per object and reason",
+			qps:   0.001,
+			burst: 2,
+			events: []event{
+				{uid: "a", reason: "Failed", message: "1", want: true},
+				{uid: "a", reason: "Failed", message: "2", want: true},
+				{uid: "a", reason: "Failed", message: "3", want: false},
+				{uid: "a", reason: "Other", message: "4", want: true},
+				{uid: "b", reason: "Failed", message: "5", want: true},
+			},
+		},
+		{
+			name:   "duplicates don't consume the rate limit",
+			window: time.Hour,
+			qps:    0.001,
+			burst:  2,
+			events: []event{
+				{uid: "a", reason: "Failed", message: "1", want: true},
+				{uid: "a", reason: "Failed", message: "1", want: false},
+				{uid: "a", reason: "Failed", message: "2", want: true},
+				{uid: "a", reason: "Failed", message: "3", want: false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newEventLimiter(tt.window, tt.qps, tt.burst)
+			for i, e := range tt.events {
+				if got := l.allow(e.uid, e.reason, e.message); got != e.want {
+					t.Errorf("event %d: allow() = %v, want %v", i, got, e.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEventLimiterGC(t *testing.T) {
+	l := newEventLimiter(time.Minute, 0, 0)
+	l.allow("deleted", "Failed", "boom")
+	l.allow("stale", "Failed", "boom")
+	// the events were recorded before the window
+	for key := range l.seen {
+		l.seen[key] = time.Now().Add(-2 * time.Minute)
+	}
+	l.lastGC = time.Now().Add(-2 * time.Minute)
+
+	if !l.allow("stale", "Failed", "boom") {
+		t.Error("allow() = false after the window, want true")
+	}
+	if _, ok := l.seen[eventKey{uid: "deleted", reason: "Failed", message: "boom"}]; ok {
+		t.Error("stale event not forgotten")
+	}
+}
+
+func TestImmutableFailEventDeduplicated(t *testing.T) {
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "ns", UID: "uid"}}
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"}}
+	recorder := record.NewFakeRecorder(10)
+	r := New(nil, scheme.Scheme, recorder, "test")
+
+	err := apierrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "cm", field.ErrorList{
+		field.Invalid(field.NewPath("data"), nil, "field is immutable"),
+	})
+	for i := 0; i < 3; i++ {
+		if _, err := r.manageImmutableChange(context.Background(), owner, obj, err); err == nil {
+			t.Fatal("manageImmutableChange() = nil, want an error")
+		}
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("%d events recorded, want 1", len(recorder.Events))
+	}
+}
@@ -1,10 +1,40 @@
 package reconciler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+const (
+	// FinalizingCondition is set on the owner while FinalizeResources waits for its children to be deleted
+	FinalizingCondition apis.ConditionType = "Finalizing"
+	// WaitingForChildrenReason is the reason of the Finalizing condition while children are still present
+	WaitingForChildrenReason apis.ConditionReason = "WaitingForChildren"
+	// CleanupSkippedCondition records on a resource being deleted the finalizers removed without cleanup
+	CleanupSkippedCondition apis.ConditionType = "CleanupSkipped"
+	// ForceFinalizedReason is the reason of the CleanupSkipped condition when cleanup is skipped
+	// because of the ForceFinalizeAnnotation
+	ForceFinalizedReason apis.ConditionReason = "ForceFinalized"
+	// CleanupDeadlineExceededReason is the reason of the CleanupSkipped condition when cleanup is skipped
+	// because the deadline of the finalizer is exceeded
+	CleanupDeadlineExceededReason apis.ConditionReason = "CleanupDeadlineExceeded"
+
+	// ForceFinalizeAnnotation makes IsFinalized remove finalizers without running their cleanup. Its value
+	// is either "true", for all the finalizers, or a comma separated list of finalizer names
+	ForceFinalizeAnnotation = "toolkit/force-finalize"
+)
+
 // IsBeingDeleted returns whether this object has been requested to be deleted
 func IsBeingDeleted(obj metav1.Object) bool {
 	return !obj.GetDeletionTimestamp().IsZero()
@@ -29,3 +59,263 @@ func AddFinalizer(obj metav1.Object, finalizer string) {
 func RemoveFinalizer(obj metav1.Object, finalizer string) {
 	controllerutil.RemoveFinalizer(obj, finalizer)
 }
+
+// Finalizer is a named cleanup step driven by IsFinalized
+type Finalizer struct {
+	// Name is the finalizer set on the resources, such as example.com/external-db
+	Name string
+	// LegacyNames are former names of the finalizer. Resources carrying them are migrated to Name,
+	// or cleaned up as if they carried Name when they are being deleted
+	LegacyNames []string
+	// Order sorts the finalizers, lowest first. Finalizers with the same order run in registration order
+	Order int
+	// Clean releases what the finalizer protects. The finalizer is removed once it returns nil
+	Clean func(ctx context.Context, obj Resource) error
+	// RetryAfter turns cleanup errors into retriable errors requeued after the given interval, if not zero
+	RetryAfter time.Duration
+	// Deadline, if not zero, is how long after the deletion of the resource cleanup is attempted. Once
+	// exceeded the finalizer is removed without cleanup
+	Deadline time.Duration
+}
+
+// names returns the current and legacy names of the finalizer
+func (f Finalizer) names() []string {
+	return append([]string{f.Name}, f.LegacyNames...)
+}
+
+// isSetOn returns whether obj carries the finalizer, under its current or a legacy name
+func (f Finalizer) isSetOn(obj metav1.Object) bool {
+	for _, name := range f.names() {
+		if HasFinalizer(obj, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsMigration returns whether obj lacks the finalizer or carries a legacy name of it
+func (f Finalizer) needsMigration(obj metav1.Object) bool {
+	if !HasFinalizer(obj, f.Name) {
+		return true
+	}
+	for _, name := range f.LegacyNames {
+		if HasFinalizer(obj, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isForcedOn returns whether the ForceFinalizeAnnotation of obj selects the finalizer
+func (f Finalizer) isForcedOn(obj metav1.Object) bool {
+	value, ok := obj.GetAnnotations()[ForceFinalizeAnnotation]
+	if !ok {
+		return false
+	}
+	if strings.TrimSpace(value) == "true" {
+		return true
+	}
+	for _, forced := range strings.Split(value, ",") {
+		for _, name := range f.names() {
+			if strings.TrimSpace(forced) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// skipReason returns why the cleanup of the finalizer must be skipped for obj, if it must
+func (f Finalizer) skipReason(obj metav1.Object) (apis.ConditionReason, string, bool) {
+	if f.isForcedOn(obj) {
+		return ForceFinalizedReason, fmt.Sprintf("cleanup of %s forced by the %s annotation", f.Name, ForceFinalizeAnnotation), true
+	}
+	if f.Deadline > 0 && IsBeingDeleted(obj) {
+		if elapsed := time.Since(obj.GetDeletionTimestamp().Time); elapsed > f.Deadline {
+			return CleanupDeadlineExceededReason, fmt.Sprintf("cleanup of %s not completed within %s", f.Name, f.Deadline), true
+		}
+	}
+	return "", "", false
+}
+
+// finalizersFor returns the registered finalizers sorted by order, preceded by the default finalizer
+// named after the controller when clean is not nil
+func (r *Reconciler) finalizersFor(clean func() error) []Finalizer {
+	finalizers := make([]Finalizer, 0, len(r.finalizers)+1)
+	if clean != nil {
+		finalizers = append(finalizers, Finalizer{
+			Name:  r.finalizer,
+			Clean: func(context.Context, Resource) error { return clean() },
+		})
+	}
+	finalizers = append(finalizers, r.finalizers...)
+	sort.SliceStable(finalizers, func(a, b int) bool {
+		return finalizers[a].Order < finalizers[b].Order
+	})
+	return finalizers
+}
+
+// IsFinalized setup finalizers and updates the resource. It returns true when the resource is not being
+// deleted and carries all its finalizers.
+// The finalizers are the ones registered with WithFinalizers and, if clean is not nil, the default one named
+// after the controller. When the resource is being deleted, their cleanups run in order and each finalizer is
+// removed as soon as its cleanup succeeds. Cleanup is skipped for the finalizers selected by the
+// ForceFinalizeAnnotation or whose deadline is exceeded, with a Warning event and the CleanupSkipped condition.
+// In dry-run mode missing finalizers are considered set once the dry-run write succeeds.
+// Finalizer writes are guarded by the resource version, conflicts are retried with the configured backoff,
+// re-fetching the resource
+func (r *Reconciler) IsFinalized(ctx context.Context, obj Resource, clean func() error) (bool, error) {
+	finalizers := r.finalizersFor(clean)
+
+	if IsBeingDeleted(obj) {
+		for _, f := range finalizers {
+			if !f.isSetOn(obj) {
+				continue
+			}
+			log := r.loggerFor(obj).WithValues("finalizer", f.Name)
+
+			if reason, message, skip := f.skipReason(obj); skip {
+				r.recordSkippedCleanup(ctx, obj, reason, message)
+			} else if err := f.Clean(ctx, obj); err != nil {
+				log.Error(err, "Unable to cleanup finalizer")
+				if f.RetryAfter > 0 {
+					err = NewRetriableError(f.RetryAfter, err)
+				}
+				return false, err
+			}
+
+			log.V(2).Info("Removing finalizer")
+			err := r.updateFinalizers(ctx, obj, func(obj Resource) {
+				for _, name := range f.names() {
+					RemoveFinalizer(obj, name)
+				}
+			})
+			if err != nil {
+				log.Error(err, "Unable to remove finalizer")
+				return false, err
+			}
+		}
+		return false, nil
+	}
+
+	missing := []Finalizer{}
+	for _, f := range finalizers {
+		if f.needsMigration(obj) {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		log := r.loggerFor(obj)
+		log.V(2).Info("Adding finalizers")
+		err := r.updateFinalizers(ctx, obj, func(obj Resource) {
+			for _, f := range missing {
+				for _, name := range f.LegacyNames {
+					RemoveFinalizer(obj, name)
+				}
+				AddFinalizer(obj, f.Name)
+			}
+		})
+		if err != nil {
+			log.Error(err, "Unable to add finalizers")
+			return false, err
+		}
+		// dry-run writes are not persisted, the resource would otherwise never be found finalized
+		return r.IsDryRun(ctx), nil
+	}
+
+	return true, nil
+}
+
+// recordSkippedCleanup reports a finalizer removed without cleanup with a Warning event and, if obj has
+// conditions, in its CleanupSkipped condition, which accumulates the skipped finalizers
+func (r *Reconciler) recordSkippedCleanup(ctx context.Context, obj Resource, reason apis.ConditionReason, message string) {
+	log := r.loggerFor(obj)
+	log.Info("Skipping finalizer cleanup", "reason", reason, "message", message)
+	r.recordEvent(obj, corev1.EventTypeWarning, string(reason), message)
+
+	conditionsStatusAware, ok := obj.(conditionsStatusAware)
+	if !ok {
+		return
+	}
+	if getter, ok := obj.(conditionsGetter); ok {
+		if previous := getter.GetConditions().GetCondition(CleanupSkippedCondition); previous != nil && previous.IsTrue() {
+			message = previous.Message + "; " + message
+		}
+	}
+	changed := conditionsStatusAware.SetCondition(apis.Condition{
+		Type:    CleanupSkippedCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	if changed {
+		if err := r.updateStatus(ctx, obj); err != nil {
+			log.Error(err, "Unable to update status")
+		}
+	}
+}
+
+// FinalizeResources deletes the children of owner and waits for them to be actually gone, e.g. for
+// PersistentVolumeClaims or cloud load balancers running their own finalizers. It is meant to be called from
+// the clean function of IsFinalized: while children are still present, the Finalizing condition of the owner
+// lists them and a retriable error is returned, so that the finalizer is kept and the reconcile is requeued
+// with a backoff growing with the time elapsed since the deletion of the owner.
+// Once the finalization timeout (see WithFinalizationTimeout) is exceeded, the remaining children are
+// abandoned with a Warning event and nil is returned
+func (r *Reconciler) FinalizeResources(ctx context.Context, owner Resource, children []Resource, opts ...client.DeleteOption) error {
+	log := r.loggerFor(owner)
+
+	remaining := []string{}
+	for _, child := range children {
+		res, err := r.DeleteResourceIfExists(ctx, owner, child, opts...)
+		var conflictErr *OwnershipConflictError
+		if errors.As(err, &conflictErr) {
+			log.Info("Child is not controlled by the owner, not waiting for it", "child", conflictErr.Object)
+			continue
+		} else if err != nil {
+			return err
+		}
+		if res != OperationResultNotFound {
+			gvk := newResourceResult(r.scheme, child, res).GVK
+			remaining = append(remaining, fmt.Sprintf("%s %s", gvk.Kind, path.Join(child.GetNamespace(), child.GetName())))
+		}
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+
+	var elapsed time.Duration
+	if IsBeingDeleted(owner) {
+		elapsed = time.Since(owner.GetDeletionTimestamp().Time)
+	}
+	message := "waiting for deletion of " + strings.Join(remaining, ", ")
+	if r.finalizationTimeout > 0 && elapsed > r.finalizationTimeout {
+		log.Info("Finalization timeout exceeded, abandoning children", "children", remaining)
+		r.recordEvent(owner, corev1.EventTypeWarning, "FinalizationTimeout", "Timeout exceeded "+message)
+		return nil
+	}
+
+	if conditionsStatusAware, ok := owner.(conditionsStatusAware); ok {
+		changed := conditionsStatusAware.SetCondition(apis.Condition{
+			Type:    FinalizingCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  WaitingForChildrenReason,
+			Message: message,
+		})
+		if changed {
+			if err := r.updateStatus(ctx, owner); err != nil {
+				log.Error(err, "Unable to update status")
+			}
+		}
+	}
+
+	// requeue after the elapsed time, doubling the wait at every attempt
+	retryAfter := elapsed
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	} else if retryAfter > r.finalizationMaxBackoff {
+		retryAfter = r.finalizationMaxBackoff
+	}
+	log.V(1).Info("Waiting for children deletion", "children", remaining, "retryAfter", retryAfter)
+	return NewRetriableError(retryAfter, errors.New(message))
+}
@@ -0,0 +1,278 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// lockingClient rejects patches carrying a stale resource version, as the API server does.
+// The fake client does not check resource versions
+type lockingClient struct {
+	client.Client
+}
+
+func (c lockingClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	var p struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	accessor := obj.(Resource)
+	live := obj.DeepCopyObject()
+	if err := c.Get(ctx, types.NamespacedName{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}, live); err != nil {
+		return err
+	}
+	if rv := p.Metadata.ResourceVersion; rv != "" && rv != live.(Resource).GetResourceVersion() {
+		return apierrors.NewConflict(corev1.Resource("configmaps"), accessor.GetName(), nil)
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestIsFinalizedKeepsConcurrentFinalizers(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "ns", Name: "cm"}
+	c := lockingClient{fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns", ResourceVersion: "1"}})}
+	r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test")
+
+	stale := &corev1.ConfigMap{}
+	if err := c.Get(ctx, key, stale); err != nil {
+		t.Fatal(err)
+	}
+	// another controller adds its finalizer after the resource has been read
+	latest := stale.DeepCopy()
+	AddFinalizer(latest, "other")
+	if err := c.Update(ctx, latest); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.IsFinalized(ctx, stale, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	live := &corev1.ConfigMap{}
+	if err := c.Get(ctx, key, live); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"other", "test"} {
+		if !HasFinalizer(live, name) {
+			t.Errorf("finalizer %q missing, got %v", name, live.GetFinalizers())
+		}
+	}
+}
+
+func TestIsFinalizedOrder(t *testing.T) {
+	ctx := context.Background()
+	now := metav1.Now()
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "cm", Namespace: "ns", DeletionTimestamp: &now,
+		Finalizers: []string{"example.com/b", "test", "example.com/a", "example.com/c"},
+	}}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, obj.DeepCopy())
+
+	cleaned := []string{}
+	clean := func(name string, err error) func(context.Context, Resource) error {
+		return func(context.Context, Resource) error {
+			cleaned = append(cleaned, name)
+			return err
+		}
+	}
+	r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test", WithFinalizers(
+		Finalizer{Name: "example.com/b", Order: 2, Clean: clean("example.com/b", nil)},
+		Finalizer{Name: "example.com/c", Order: 3, Clean: clean("example.com/c", errors.New("failed"))},
+		Finalizer{Name: "example.com/a", Order: 1, Clean: clean("example.com/a", nil)},
+	))
+
+	if _, err := r.IsFinalized(ctx, obj, func() error { return clean("test", nil)(ctx, obj) }); err == nil {
+		t.Fatal("IsFinalized() = nil, want the cleanup error")
+	}
+	want := []string{"test", "example.com/a", "example.com/b", "example.com/c"}
+	if !reflect.DeepEqual(cleaned, want) {
+		t.Errorf("cleanup order = %v, want %v", cleaned, want)
+	}
+	live := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cm"}, live); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(live.GetFinalizers(), []string{"example.com/c"}) {
+		t.Errorf("finalizers = %v, want the failed one only", live.GetFinalizers())
+	}
+}
+
+func TestIsFinalizedLegacyNames(t *testing.T) {
+	tests := []struct {
+		name           string
+		deleting       bool
+		finalizers     []string
+		wantFinalized  bool
+		wantCleaned    bool
+		wantFinalizers []string
+	}{
+		{
+			name:           "legacy name migrated",
+			finalizers:     []string{"old", "other"},
+			wantFinalizers: []string{"other", "example.com/new"},
+		},
+		{
+			name:           "both names",
+			finalizers:     []string{"old", "example.com/new"},
+			wantFinalizers: []string{"example.com/new"},
+		},
+		{
+			name:           "migrated",
+			finalizers:     []string{"example.com/new"},
+			wantFinalized:  true,
+			wantFinalizers: []string{"example.com/new"},
+		},
+		{
+			name:           "cleaned up under the legacy name",
+			deleting:       true,
+			finalizers:     []string{"old", "other"},
+			wantCleaned:    true,
+			wantFinalizers: []string{"other"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns", Finalizers: tt.finalizers}}
+			if tt.deleting {
+				now := metav1.Now()
+				obj.DeletionTimestamp = &now
+			}
+			c := fake.NewFakeClientWithScheme(scheme.Scheme, obj.DeepCopy())
+			cleaned := false
+			r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test", WithFinalizers(Finalizer{
+				Name:        "example.com/new",
+				LegacyNames: []string{"old"},
+				Clean:       func(context.Context, Resource) error { cleaned = true; return nil },
+			}))
+
+			finalized, err := r.IsFinalized(ctx, obj, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if finalized != tt.wantFinalized || cleaned != tt.wantCleaned {
+				t.Errorf("IsFinalized() = %v, cleaned = %v, want %v, %v", finalized, cleaned, tt.wantFinalized, tt.wantCleaned)
+			}
+			live := &corev1.ConfigMap{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cm"}, live); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(live.GetFinalizers(), tt.wantFinalizers) {
+				t.Errorf("finalizers = %v, want %v", live.GetFinalizers(), tt.wantFinalizers)
+			}
+		})
+	}
+}
+
+func TestIsFinalizedSkipsCleanup(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		deletedAgo  time.Duration
+		deadline    time.Duration
+		wantCleaned bool
+		wantReason  apis.ConditionReason
+	}{
+		{
+			name:        "cleaned up",
+			deadline:    time.Hour,
+			deletedAgo:  time.Minute,
+			wantCleaned: true,
+		},
+		{
+			name:       "deadline exceeded",
+			deadline:   time.Minute,
+			deletedAgo: time.Hour,
+			wantReason: CleanupDeadlineExceededReason,
+		},
+		{
+			name:        "all finalizers forced",
+			annotations: map[string]string{ForceFinalizeAnnotation: "true"},
+			wantReason:  ForceFinalizedReason,
+		},
+		{
+			name:        "finalizer forced by name",
+			annotations: map[string]string{ForceFinalizeAnnotation: "example.com/other, example.com/db"},
+			wantReason:  ForceFinalizedReason,
+		},
+		{
+			name:        "finalizer forced by legacy name",
+			annotations: map[string]string{ForceFinalizeAnnotation: "old"},
+			wantReason:  ForceFinalizedReason,
+		},
+		{
+			name:        "other finalizer forced",
+			annotations: map[string]string{ForceFinalizeAnnotation: "example.com/other"},
+			wantCleaned: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			deleted := metav1.NewTime(time.Now().Add(-tt.deletedAgo))
+			obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Name: "cm", Namespace: "ns", UID: "uid", DeletionTimestamp: &deleted,
+				Annotations: tt.annotations, Finalizers: []string{"example.com/db"},
+			}}
+			c := fake.NewFakeClientWithScheme(scheme.Scheme, obj.DeepCopy())
+			recorder := record.NewFakeRecorder(10)
+			cleaned := false
+			r := New(c, scheme.Scheme, recorder, "test", WithFinalizers(Finalizer{
+				Name:        "example.com/db",
+				LegacyNames: []string{"old"},
+				Deadline:    tt.deadline,
+				Clean:       func(context.Context, Resource) error { cleaned = true; return nil },
+			}))
+
+			if _, err := r.IsFinalized(ctx, obj, nil); err != nil {
+				t.Fatal(err)
+			}
+			if cleaned != tt.wantCleaned {
+				t.Errorf("cleaned = %v, want %v", cleaned, tt.wantCleaned)
+			}
+			live := &corev1.ConfigMap{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cm"}, live); err != nil {
+				t.Fatal(err)
+			}
+			if len(live.GetFinalizers()) != 0 {
+				t.Errorf("finalizers = %v, want none", live.GetFinalizers())
+			}
+			if tt.wantReason == "" {
+				if len(recorder.Events) != 0 {
+					t.Errorf("unexpected event %q", <-recorder.Events)
+				}
+				return
+			}
+			if len(recorder.Events) != 1 {
+				t.Fatalf("%d events recorded, want 1", len(recorder.Events))
+			}
+			if event := <-recorder.Events; !strings.HasPrefix(event, corev1.EventTypeWarning+" "+string(tt.wantReason)+" ") {
+				t.Errorf("event = %q, want a %s warning", event, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DependenciesReadyCondition is the condition type set on the owner by ApplyResourceGraph
+	DependenciesReadyCondition apis.ConditionType = "DependenciesReady"
+	// DependencyNotReadyReason is the condition reason reported when a node of the graph waits for its dependencies
+	DependencyNotReadyReason apis.ConditionReason = "DependencyNotReady"
+)
+
+// ResourceNode is a desired child resource of a ResourceGraph
+type ResourceNode struct {
+	// Name identifies the node in the graph
+	Name string
+	// Object is the desired resource, applied with CreateOrUpdateResource
+	Object Resource
+	// DependsOn lists the names of the nodes which must be ready before the resource is applied
+	DependsOn []string
+	// Ready reports whether the applied resource can be used by its dependents.
+	// If nil, the readiness check registered for the kind of the resource is used
+	Ready func(obj Resource) (bool, error)
+}
+
+// ResourceGraph is a set of child resources with dependencies between them
+type ResourceGraph struct {
+	nodes []*ResourceNode
+}
+
+// NewResourceGraph allocates an empty resource graph
+func NewResourceGraph() *ResourceGraph {
+	return &ResourceGraph{}
+}
+
+// Add adds a resource to the graph, depending on the given nodes. The added node is returned
+func (g *ResourceGraph) Add(name string, obj Resource, dependsOn ...string) *ResourceNode {
+	node := &ResourceNode{
+		Name:      name,
+		Object:    obj,
+		DependsOn: dependsOn,
+	}
+	g.nodes = append(g.nodes, node)
+	return node
+}
+
+// Sort returns the nodes in topological order. Independent nodes keep the order they were added in.
+// An error is returned if names are duplicated, a dependency is unknown or the graph has cycles
+func (g *ResourceGraph) Sort() ([]*ResourceNode, error) {
+	byName := make(map[string]*ResourceNode, len(g.nodes))
+	for _, node := range g.nodes {
+		if _, ok := byName[node.Name]; ok {
+			return nil, fmt.Errorf("duplicated resource graph node %q", node.Name)
+		}
+		byName[node.Name] = node
+	}
+
+	pending := make(map[string]int, len(g.nodes))
+	dependents := make(map[string][]*ResourceNode, len(g.nodes))
+	for _, node := range g.nodes {
+		for _, dep := range node.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("resource graph node %q depends on unknown node %q", node.Name, dep)
+			}
+			pending[node.Name]++
+			dependents[dep] = append(dependents[dep], node)
+		}
+	}
+
+	sorted := make([]*ResourceNode, 0, len(g.nodes))
+	done := make(map[string]bool, len(g.nodes))
+	for len(sorted) < len(g.nodes) {
+		progress := false
+		for _, node := range g.nodes {
+			if done[node.Name] || pending[node.Name] > 0 {
+				continue
+			}
+			done[node.Name] = true
+			progress = true
+			sorted = append(sorted, node)
+			for _, dependent := range dependents[node.Name] {
+				pending[dependent.Name]--
+			}
+		}
+		if !progress {
+			cycle := []string{}
+			for _, node := range g.nodes {
+				if !done[node.Name] {
+					cycle = append(cycle, node.Name)
+				}
+			}
+			return nil, fmt.Errorf("resource graph has a cycle between nodes %s", strings.Join(cycle, ", "))
+		}
+	}
+	return sorted, nil
+}
+
+// ApplyResourceGraph applies the resources of the graph in topological order with CreateOrUpdateResource.
+// A resource is applied only once all its dependencies are ready, otherwise the node is blocked and
+// reported in the DependenciesReady condition of the owner, wrapped in a retriable error.
+// Graphs with cycles are rejected with a not retriable error
+func (r *Reconciler) ApplyResourceGraph(ctx context.Context, owner Resource, g *ResourceGraph) (ResourceResults, error) {
+	results := ResourceResults{}
+
+	nodes, err := g.Sort()
+	if err != nil {
+		return results, NewConditionErrorWithReason(DependenciesReadyCondition, "InvalidGraph", NewNotRetriableError(err))
+	}
+
+	hasDependents := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		for _, dep := range node.DependsOn {
+			hasDependents[dep] = true
+		}
+	}
+
+	ready := make(map[string]bool, len(nodes))
+	blocked := []string{}
+	for _, node := range nodes {
+		waiting := []string{}
+		for _, dep := range node.DependsOn {
+			if !ready[dep] {
+				waiting = append(waiting, dep)
+			}
+		}
+		if len(waiting) > 0 {
+			r.loggerFor(node.Object).V(1).Info("Resource is waiting for dependencies", "node", node.Name, "dependencies", waiting)
+			blocked = append(blocked, fmt.Sprintf("%s is waiting for %s", node.Name, strings.Join(waiting, ", ")))
+			continue
+		}
+
+		res, err := r.CreateOrUpdateResource(ctx, owner, node.Object)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, newResourceResult(r.scheme, node.Object, res))
+		if !hasDependents[node.Name] {
+			continue
+		}
+		if r.IsDryRun(ctx) {
+			// nothing is persisted in dry-run mode, go on with the dependents to report their changes too
+			ready[node.Name] = true
+			continue
+		}
+
+		// the applied object may be the desired one when the update was skipped, check the live state.
+		// A resource just created may not be visible yet
+		live := node.Object.DeepCopyObject().(Resource)
+		err = r.client.Get(ctx, types.NamespacedName{Namespace: live.GetNamespace(), Name: live.GetName()}, live)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return results, err
+		}
+		if node.Ready != nil {
+			ready[node.Name], err = node.Ready(live)
+		} else {
+			ready[node.Name], _, err = r.CheckReadiness(live)
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+
+	if len(blocked) > 0 {
+		return results, NewConditionErrorWithReason(
+			DependenciesReadyCondition,
+			DependencyNotReadyReason,
+			NewRetriableError(r.readinessRequeue, errors.New(strings.Join(blocked, "; "))),
+		)
+	}
+
+	if conditionsStatusAware, ok := owner.(conditionsStatusAware); ok {
+		conditionsStatusAware.SetCondition(apis.SuccessCondition(DependenciesReadyCondition))
+	}
+	return results, nil
+}
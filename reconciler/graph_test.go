@@ -0,0 +1,126 @@
+package reconciler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResourceGraphSort(t *testing.T) {
+	type node struct {
+		name      string
+		dependsOn []string
+	}
+	tests := []struct {
+		name    string
+		nodes   []node
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "independent nodes keep their order",
+			nodes: []node{{name: "b"}, {name: "a"}, {name: "c"}},
+			want:  []string{"b", "a", "c"},
+		},
+		{
+			name:  "dependencies first",
+			nodes: []node{{name: "deploy", dependsOn: []string{"secret", "sa"}}, {name: "sa"}, {name: "secret"}},
+			want:  []string{"sa", "secret", "deploy"},
+		},
+		{
+			name:  "chain",
+			nodes: []node{{name: "c", dependsOn: []string{"b"}}, {name: "b", dependsOn: []string{"a"}}, {name: "a"}},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:    "cycle",
+			nodes:   []node{{name: "a", dependsOn: []string{"b"}}, {name: "b", dependsOn: []string{"a"}}, {name: "c"}},
+			wantErr: "cycle between nodes a, b",
+		},
+		{
+			name:    "self dependency",
+			nodes:   []node{{name: "a", dependsOn: []string{"a"}}},
+			wantErr: "cycle between nodes a",
+		},
+		{
+			name:    "unknown dependency",
+			nodes:   []node{{name: "a", dependsOn: []string{"missing"}}},
+			wantErr: `depends on unknown node "missing"`,
+		},
+		{
+			name:    "duplicated name",
+			nodes:   []node{{name: "a"}, {name: "a"}},
+			wantErr: `duplicated resource graph node "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewResourceGraph()
+			for _, n := range tt.nodes {
+				g.Add(n.name, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: n.name}}, n.dependsOn...)
+			}
+			sorted, err := g.Sort()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Sort() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, node := range sorted {
+				got = append(got, node.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Sort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyResourceGraph(t *testing.T) {
+	ctx := context.Background()
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "ns", UID: "uid"}}
+	r := New(fake.NewFakeClientWithScheme(scheme.Scheme), scheme.Scheme, record.NewFakeRecorder(10), "test")
+
+	g := NewResourceGraph()
+	g.Add("a", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+	b := g.Add("b", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b"}}, "a")
+	b.Ready = func(Resource) (bool, error) { return false, nil }
+	g.Add("c", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "c"}}, "b")
+
+	results, err := r.ApplyResourceGraph(ctx, owner, g)
+	if len(results) != 2 {
+		t.Errorf("results = %+v, want a and b applied", results)
+	}
+	conditionErr, ok := err.(*ConditionError)
+	if !ok || conditionErr.Reason != DependencyNotReadyReason || !strings.Contains(err.Error(), "c is waiting for b") {
+		t.Errorf("error = %v, want c blocked by b", err)
+	}
+}
+
+func TestApplyResourceGraphDryRun(t *testing.T) {
+	ctx := context.Background()
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "ns", UID: "uid"}}
+	r := New(fake.NewFakeClientWithScheme(scheme.Scheme), scheme.Scheme, record.NewFakeRecorder(10), "test", WithDryRun())
+
+	g := NewResourceGraph()
+	g.Add("a", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+	g.Add("b", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b"}}, "a")
+
+	results, err := r.ApplyResourceGraph(ctx, owner, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Errorf("results = %+v, want a and b applied", results)
+	}
+}
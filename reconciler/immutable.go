@@ -0,0 +1,237 @@
+package reconciler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// ImmutableFieldReason is the condition and event reason reported when an update changes immutable fields
+	ImmutableFieldReason apis.ConditionReason = "ImmutableFieldChanged"
+
+	// ReplacesLabel and ReplacesAnnotation are set by ImmutableReplace on replacements. The annotation holds
+	// the name of the replaced resource, the label a value derived from it used to look replacements up
+	ReplacesLabel      = "toolkit/replaces"
+	ReplacesAnnotation = "toolkit/replaces"
+)
+
+// ImmutablePolicy is the action taken by CreateOrUpdateResource when an update is rejected because it
+// changes immutable fields (Job templates, Deployment selectors, StatefulSet volumeClaimTemplates...)
+type ImmutablePolicy string
+
+const (
+	// ImmutableFail returns a not retriable ConditionError
+	ImmutableFail ImmutablePolicy = "Fail"
+	// ImmutableRecreate deletes the resource, with background propagation, and creates it again
+	ImmutableRecreate ImmutablePolicy = "Recreate"
+	// ImmutableRecreateOrphan operates as ImmutableRecreate, orphaning the dependents of the deleted resource
+	ImmutableRecreateOrphan ImmutablePolicy = "RecreateOrphan"
+	// ImmutableReplace creates a replacement named after the resource with a suffix derived from its content,
+	// then deletes the original resource. The passed object is renamed accordingly. Callers keep passing the
+	// original name: the helpers resolve it to the latest replacement, found by ReplacesLabel
+	ImmutableReplace ImmutablePolicy = "Replace"
+)
+
+// forbiddenSpecUpdateMessage is the message of the updates rejected by the StatefulSet validation
+const forbiddenSpecUpdateMessage = "spec for fields other than"
+
+// isImmutableFieldError returns whether err is an Invalid error caused by the change of an immutable field,
+// either reported as such or as a forbidden change of the spec (StatefulSet volumeClaimTemplates...)
+func isImmutableFieldError(err error) bool {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Reason != metav1.StatusReasonInvalid {
+		return false
+	}
+	if status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			if strings.Contains(cause.Message, "immutable") {
+				return true
+			}
+			if cause.Type == metav1.CauseType(field.ErrorTypeForbidden) &&
+				(cause.Field == "spec" || strings.HasPrefix(cause.Field, "spec.")) {
+				return true
+			}
+		}
+	}
+	return strings.Contains(err.Error(), "immutable") || strings.Contains(err.Error(), forbiddenSpecUpdateMessage)
+}
+
+// immutablePolicyFor returns the immutable policy configured for the kind of obj
+func (r *Reconciler) immutablePolicyFor(obj Resource) ImmutablePolicy {
+	if gvk, err := apiutil.GVKForObject(obj, r.scheme); err == nil {
+		if policy, ok := r.immutablePolicies[gvk]; ok {
+			return policy
+		}
+	}
+	return ImmutableFail
+}
+
+// manageImmutableChange applies the immutable policy of the kind of obj after its update failed with err.
+// The action is recorded as an event on the owner
+func (r *Reconciler) manageImmutableChange(ctx context.Context, owner, obj Resource, err error) (OperationResult, error) {
+	log := r.loggerFor(obj)
+	policy := r.immutablePolicyFor(obj)
+	event := func(eventtype, action string) {
+		if owner != nil {
+			res := newResourceResult(r.scheme, obj, OperationResultNone)
+			r.recordEvent(owner, eventtype, string(ImmutableFieldReason), fmt.Sprintf("%s %s %s: %s", action, res.GVK.Kind, res.NamespacedName, err.Error()))
+		}
+	}
+
+	switch policy {
+	case ImmutableRecreate, ImmutableRecreateOrphan:
+		propagation := metav1.DeletePropagationBackground
+		if policy == ImmutableRecreateOrphan {
+			propagation = metav1.DeletePropagationOrphan
+		}
+		log.Info("Immutable fields changed, recreating resource", "propagation", propagation)
+		event(corev1.EventTypeNormal, "Recreating")
+		if err := r.delete(ctx, obj, client.PropagationPolicy(propagation)); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to delete object")
+			return OperationResultNone, err
+		}
+		obj.SetResourceVersion("")
+		if err := r.create(ctx, obj); err != nil && apierrors.IsAlreadyExists(err) {
+			// the deleted resource is still being finalized
+			return OperationResultNone, NewRetriableError(r.readinessRequeue, err)
+		} else if err != nil {
+			log.Error(err, "unable to create object")
+			return OperationResultNone, err
+		}
+		return OperationResultRecreated, nil
+
+	case ImmutableReplace:
+		// the replaced resource is either the original one or a previous replacement
+		replaced := obj.DeepCopyObject().(Resource)
+		base := obj.GetName()
+		if name, ok := obj.GetAnnotations()[ReplacesAnnotation]; ok {
+			base = name
+		}
+		obj.SetName(base)
+		setReplaces(obj, base)
+		hash, err := hashObject(obj)
+		if err != nil {
+			return OperationResultNone, err
+		}
+		obj.SetName(fmt.Sprintf("%s-%s", base, hash[:8]))
+		obj.SetResourceVersion("")
+		if _, err := r.setLastAppliedHash(obj); err != nil {
+			return OperationResultNone, err
+		}
+		log.Info("Immutable fields changed, replacing resource", "replacement", obj.GetName())
+		event(corev1.EventTypeNormal, "Replacing")
+		if err := r.create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+			log.Error(err, "unable to create replacement object")
+			return OperationResultNone, err
+		}
+		if err := r.delete(ctx, replaced, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to delete replaced object")
+			return OperationResultNone, err
+		}
+		r.forgetInventory(owner, replaced)
+		return OperationResultReplaced, nil
+	}
+
+	log.Info("Immutable fields changed", "Error", err.Error())
+	event(corev1.EventTypeWarning, "Unable to update")
+	return OperationResultNone, NewConditionErrorWithReason(AppliedCondition, ImmutableFieldReason, NewNotRetriableError(err))
+}
+
+// replacesLabelValue returns the ReplacesLabel value for the replacements of the resource with the given name,
+// hashing the names too long to be label values
+func replacesLabelValue(name string) string {
+	if len(name) <= validation.LabelValueMaxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	return name[:validation.LabelValueMaxLength-17] + "-" + hex.EncodeToString(sum[:])[:16]
+}
+
+// setReplaces marks obj as a replacement of the resource with the given name
+func setReplaces(obj Resource, name string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ReplacesLabel] = replacesLabelValue(name)
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ReplacesAnnotation] = name
+	obj.SetAnnotations(annotations)
+}
+
+// lookupReplacement renames obj after the latest replacement of the resource it names, if its kind uses
+// ImmutableReplace and such a replacement exists. It returns the name of the replaced resource, empty if obj
+// was not renamed
+func (r *Reconciler) lookupReplacement(ctx context.Context, owner, obj Resource) (string, error) {
+	if r.immutablePolicyFor(obj) != ImmutableReplace {
+		return "", nil
+	}
+	name := obj.GetName()
+	if replaced, ok := obj.GetAnnotations()[ReplacesAnnotation]; ok {
+		name = replaced
+	}
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return "", err
+	}
+	replacements, err := r.listKind(ctx, gvk, obj.GetNamespace(), client.MatchingLabels{ReplacesLabel: replacesLabelValue(name)})
+	if err != nil {
+		return "", err
+	}
+	var latest Resource
+	for _, replacement := range replacements {
+		if replacement.GetAnnotations()[ReplacesAnnotation] != name || (owner != nil && !IsControlledBy(owner, replacement)) {
+			continue
+		}
+		if latest == nil || latest.GetCreationTimestamp().Time.Before(replacement.GetCreationTimestamp().Time) {
+			latest = replacement
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	obj.SetName(latest.GetName())
+	setReplaces(obj, name)
+	return name, nil
+}
+
+// resolveReplacement renames obj as lookupReplacement does. A replaced resource left behind is deleted
+func (r *Reconciler) resolveReplacement(ctx context.Context, owner, obj Resource) error {
+	name, err := r.lookupReplacement(ctx, owner, obj)
+	if err != nil || name == "" {
+		return err
+	}
+
+	// the replaced resource may have survived a failed cutover
+	original := obj.DeepCopyObject().(Resource)
+	err = r.client.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}, original)
+	if err == nil && (owner == nil || IsControlledBy(owner, original)) {
+		r.loggerFor(original).Info("Deleting replaced resource", "replacement", obj.GetName())
+		if err := r.delete(ctx, original, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		r.forgetInventory(owner, original)
+	} else if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
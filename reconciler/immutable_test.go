@@ -0,0 +1,139 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsImmutableFieldError(t *testing.T) {
+	gk := schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "immutable field",
+			err: apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "deploy", field.ErrorList{
+				field.Invalid(field.NewPath("spec", "selector"), nil, "field is immutable"),
+			}),
+			want: true,
+		},
+		{
+			name: "forbidden statefulset spec update",
+			err: apierrors.NewInvalid(gk, "sts", field.ErrorList{
+				field.Forbidden(field.NewPath("spec"), "updates to statefulset spec for fields other than 'replicas', 'template', and 'updateStrategy' are forbidden"),
+			}),
+			want: true,
+		},
+		{
+			name: "wrapped immutable field",
+			err: fmt.Errorf("update failed: %w", apierrors.NewInvalid(gk, "sts", field.ErrorList{
+				field.Forbidden(field.NewPath("spec"), "forbidden"),
+			})),
+			want: true,
+		},
+		{
+			name: "invalid value",
+			err: apierrors.NewInvalid(gk, "sts", field.ErrorList{
+				field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+			}),
+			want: false,
+		},
+		{
+			name: "forbidden metadata update",
+			err: apierrors.NewInvalid(gk, "sts", field.ErrorList{
+				field.Forbidden(field.NewPath("metadata", "labels"), "forbidden"),
+			}),
+			want: false,
+		},
+		{
+			name: "other error",
+			err:  errors.New("immutable"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isImmutableFieldError(tt.err); got != tt.want {
+				t.Errorf("isImmutableFieldError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveReplacement(t *testing.T) {
+	ctx := context.Background()
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "ns", UID: "uid"}}
+	newObj := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+	}
+	original := newObj("cm")
+	original.Labels = map[string]string{OwnerUIDLabel: "uid"}
+	replacement := newObj("cm-0123abcd")
+	replacement.Labels = map[string]string{OwnerUIDLabel: "uid"}
+	setReplaces(replacement, "cm")
+
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, original, replacement)
+	r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test",
+		WithImmutablePolicy(corev1.SchemeGroupVersion.WithKind("ConfigMap"), ImmutableReplace))
+
+	obj := newObj("cm")
+	if err := r.resolveReplacement(ctx, owner, obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetName() != "cm-0123abcd" {
+		t.Errorf("name = %q, want %q", obj.GetName(), "cm-0123abcd")
+	}
+	if obj.GetAnnotations()[ReplacesAnnotation] != "cm" {
+		t.Errorf("replaces annotation = %q, want %q", obj.GetAnnotations()[ReplacesAnnotation], "cm")
+	}
+	err := c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cm"}, &corev1.ConfigMap{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("replaced resource not deleted: %v", err)
+	}
+
+	unrelated := newObj("other")
+	if err := r.resolveReplacement(ctx, owner, unrelated); err != nil {
+		t.Fatal(err)
+	}
+	if unrelated.GetName() != "other" {
+		t.Errorf("name = %q, want %q", unrelated.GetName(), "other")
+	}
+}
+
+func TestPruneDryRunReplacement(t *testing.T) {
+	ctx := context.Background()
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "ns", UID: "uid"}}
+	original := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns", Labels: map[string]string{OwnerUIDLabel: "uid"}}}
+	replacement := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm-0123abcd", Namespace: "ns", Labels: map[string]string{OwnerUIDLabel: "uid"}}}
+	setReplaces(replacement, "cm")
+
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, original, replacement)
+	r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test",
+		WithImmutablePolicy(corev1.SchemeGroupVersion.WithKind("ConfigMap"), ImmutableReplace))
+
+	desired := []Resource{&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"}}}
+	results, err := r.PruneResources(ctx, owner, desired, PruneDryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].NamespacedName.Name != "cm" {
+		t.Errorf("results = %+v, want the replaced resource", results)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cm"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("replaced resource deleted in dry-run mode: %v", err)
+	}
+}
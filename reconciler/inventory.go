@@ -0,0 +1,108 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resourceRef returns the inventory reference of obj
+func (r *Reconciler) resourceRef(obj Resource) apis.ResourceRef {
+	res := newResourceResult(r.scheme, obj, OperationResultNone)
+	return apis.NewResourceRef(res.GVK, res.NamespacedName.Namespace, res.NamespacedName.Name)
+}
+
+// recordInventory adds obj to the inventory of owner, if the owner status supports it
+func (r *Reconciler) recordInventory(owner, obj Resource) {
+	if owner == nil {
+		return
+	}
+	if inventoryStatusAware, ok := owner.(inventoryStatusAware); ok {
+		inventory := inventoryStatusAware.GetInventory()
+		if inventory.Add(r.resourceRef(obj)) {
+			r.loggerFor(owner).V(2).Info("Adding resource to inventory", "resource", r.resourceRef(obj))
+			inventoryStatusAware.SetInventory(inventory)
+		}
+	}
+}
+
+// forgetInventory removes obj from the inventory of owner, if the owner status supports it
+func (r *Reconciler) forgetInventory(owner, obj Resource) {
+	if owner == nil {
+		return
+	}
+	if inventoryStatusAware, ok := owner.(inventoryStatusAware); ok {
+		inventory := inventoryStatusAware.GetInventory()
+		if inventory.Remove(r.resourceRef(obj)) {
+			r.loggerFor(owner).V(2).Info("Removing resource from inventory", "resource", r.resourceRef(obj))
+			inventoryStatusAware.SetInventory(inventory)
+		}
+	}
+}
+
+// PruneInventory deletes the resources recorded in the inventory of owner which are not in the desired list,
+// and removes them from the inventory. Unlike PruneResources it does not rely on owner references, so it
+// works for cluster scoped and cross namespace resources.
+// The resources are recorded in the inventory by the create, update, patch and apply helpers. The owner
+// status has to be written (e.g. by ManageSuccess) for the inventory to be persisted.
+// The pruned resources (or the ones that would be pruned in dry-run mode) are returned
+func (r *Reconciler) PruneInventory(ctx context.Context, owner Resource, desired []Resource, opts ...PruneOption) (ResourceResults, error) {
+	o := &pruneOptions{skipKinds: map[schema.GroupVersionKind]bool{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	inventoryStatusAware, ok := owner.(inventoryStatusAware)
+	if !ok {
+		return ResourceResults{}, nil
+	}
+
+	keep := map[apis.ResourceRef]bool{}
+	for _, obj := range desired {
+		if err := r.defaultNamespace(owner, obj); err != nil {
+			return ResourceResults{}, err
+		}
+		if _, err := r.lookupReplacement(ctx, owner, obj); err != nil {
+			return ResourceResults{}, err
+		}
+		keep[r.resourceRef(obj)] = true
+	}
+
+	results := ResourceResults{}
+	// DeleteResourceIfExists removes the deleted resources from the inventory, iterate over a copy
+	for _, ref := range inventoryStatusAware.GetInventory().DeepCopy() {
+		if keep[ref] || o.skipKinds[ref.GroupVersionKind()] {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(ref.GroupVersionKind())
+		obj.SetNamespace(ref.Namespace)
+		obj.SetName(ref.Name)
+		res := newResourceResult(r.scheme, obj, OperationResultDeleted)
+
+		if o.dryRun {
+			r.loggerFor(obj).Info("Resource would be pruned (dry run)", "resource", ref)
+			results = append(results, res)
+			continue
+		}
+
+		r.loggerFor(obj).V(1).Info("Pruning resource", "resource", ref)
+		var err error
+		res.Result, err = r.DeleteResourceIfExists(ctx, owner, obj)
+		var conflictErr *OwnershipConflictError
+		if errors.As(err, &conflictErr) {
+			// the resource has been replaced by someone else's, it is not managed anymore
+			r.loggerFor(obj).Info("Resource is not controlled by the owner anymore, forgetting it", "resource", ref)
+			r.forgetInventory(owner, obj)
+			continue
+		} else if err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
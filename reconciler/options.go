@@ -0,0 +1,183 @@
+package reconciler
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Option configures optional Reconciler behaviour
+type Option func(*Reconciler)
+
+// WithChangeDetection sets the default strategy used by CreateOrUpdateResource to skip no-op updates
+func WithChangeDetection(mode ChangeDetection) Option {
+	return func(r *Reconciler) {
+		r.changeDetection = mode
+	}
+}
+
+// WithKindChangeDetection overrides the change detection strategy for the given kind
+func WithKindChangeDetection(gvk schema.GroupVersionKind, mode ChangeDetection) Option {
+	return func(r *Reconciler) {
+		r.kindChangeDetection[gvk] = mode
+	}
+}
+
+// WithConflictBackoff sets the backoff used to retry writes failing with Conflict
+func WithConflictBackoff(backoff wait.Backoff) Option {
+	return func(r *Reconciler) {
+		r.conflictBackoff = backoff
+	}
+}
+
+// WithRetryBackoff sets the exponential backoff used to requeue the errors returned by NewBackoffError and the
+// API errors classified as ErrorClassBackoff. DefaultRetryBackoff is used by default
+func WithRetryBackoff(backoff wait.Backoff) Option {
+	return func(r *Reconciler) {
+		r.retries = newRetryBackoff(backoff)
+	}
+}
+
+// WithRESTMapper sets the RESTMapper used to tell cluster scoped kinds apart, which get neither the namespace nor
+// a controller reference of a namespaced owner. NewWithManager uses the RESTMapper of the manager, New defaults
+// to one mapping the kinds of the scheme as namespaced, except the built-in cluster scoped kinds
+func WithRESTMapper(mapper meta.RESTMapper) Option {
+	return func(r *Reconciler) {
+		r.restMapper = mapper
+	}
+}
+
+// WithReadinessRequeue sets the interval at which reconciles waiting for resources to become ready are requeued
+func WithReadinessRequeue(interval time.Duration) Option {
+	return func(r *Reconciler) {
+		r.readinessRequeue = interval
+	}
+}
+
+// WithNotRetriableRequeue sets the interval at which reconciles failing with not retriable errors are requeued,
+// 6 hours by default
+func WithNotRetriableRequeue(interval time.Duration) Option {
+	return func(r *Reconciler) {
+		r.notRetriableRequeue = interval
+	}
+}
+
+// WithReadinessCheck registers the readiness check for the given kind, replacing any built-in one
+func WithReadinessCheck(gvk schema.GroupVersionKind, check ReadinessCheck) Option {
+	return func(r *Reconciler) {
+		r.readiness.Register(gvk, check)
+	}
+}
+
+// WithDryRun sends every write with the dry-run flag, logging the intended changes instead of persisting them.
+// It can be overridden per call with ContextWithDryRun
+func WithDryRun() Option {
+	return func(r *Reconciler) {
+		r.dryRun = true
+	}
+}
+
+// WithDiffEvents records a Normal event on the owner for every update or patch of a child resource,
+// with the field level diff in the DiffAnnotation
+func WithDiffEvents() Option {
+	return func(r *Reconciler) {
+		r.diffEvents = true
+	}
+}
+
+// WithFullUpdates makes IsFinalized and the status helpers write the whole object with Update instead of
+// sending merge patches limited to the finalizers and the status
+func WithFullUpdates() Option {
+	return func(r *Reconciler) {
+		r.fullUpdates = true
+	}
+}
+
+// WithPatchStrategy sets the patch strategy used by CreateOrPatchResource for the given kind
+func WithPatchStrategy(gvk schema.GroupVersionKind, strategy PatchStrategy) Option {
+	return func(r *Reconciler) {
+		r.kindPatchStrategy[gvk] = strategy
+	}
+}
+
+// WithPreservedFields adds fields of the given kind that CreateOrUpdateResource copies from the live object
+// into the desired one before updating it, such as .spec.replicas for Deployments scaled by an HPA, or
+// .metadata.annotations to keep annotations injected by other tools
+func WithPreservedFields(gvk schema.GroupVersionKind, paths ...string) Option {
+	return func(r *Reconciler) {
+		r.preservedFields[gvk] = append(r.preservedFields[gvk], paths...)
+	}
+}
+
+// WithoutDefaultPreservedFields disables the DefaultPreservedFields. It must be passed before WithPreservedFields
+func WithoutDefaultPreservedFields() Option {
+	return func(r *Reconciler) {
+		r.preservedFields = map[schema.GroupVersionKind][]string{}
+	}
+}
+
+// WithImmutablePolicy sets the action taken when an update of the given kind changes immutable fields.
+// ImmutableFail is used by default
+func WithImmutablePolicy(gvk schema.GroupVersionKind, policy ImmutablePolicy) Option {
+	return func(r *Reconciler) {
+		r.immutablePolicies[gvk] = policy
+	}
+}
+
+// WithErrorClass sets how ManageError handles the Kubernetes API errors with the given reason, overriding
+// DefaultErrorClasses. ErrorClassDefault returns them to the controller
+func WithErrorClass(reason metav1.StatusReason, class ErrorClass) Option {
+	return func(r *Reconciler) {
+		r.errorClasses[reason] = class
+	}
+}
+
+// WithEventDeduplication sets the window during which ManageError does not record again an event with the same
+// reason and message for the same object, 10 minutes by default. Zero disables the deduplication
+func WithEventDeduplication(window time.Duration) Option {
+	return func(r *Reconciler) {
+		r.events.window = window
+	}
+}
+
+// WithEventRateLimit sets the rate, in events per second, and the burst of the events ManageError records for
+// each object and reason, one per minute with a burst of 5 by default. A zero rate disables the limit
+func WithEventRateLimit(qps float32, burst int) Option {
+	return func(r *Reconciler) {
+		r.events.qps = qps
+		r.events.burst = burst
+	}
+}
+
+// WithAdoptionPolicy sets which existing resources without controller can be adopted by the create helpers.
+// By default such resources are reported as ownership conflicts
+func WithAdoptionPolicy(policy AdoptionPolicy) Option {
+	return func(r *Reconciler) {
+		r.adoption = policy
+	}
+}
+
+// WithFinalizationTimeout sets how long after the deletion of the owner FinalizeResources waits for the
+// children to be gone. By default it waits forever
+func WithFinalizationTimeout(timeout time.Duration) Option {
+	return func(r *Reconciler) {
+		r.finalizationTimeout = timeout
+	}
+}
+
+// WithFinalizationMaxBackoff caps the requeue interval of FinalizeResources, one minute by default
+func WithFinalizationMaxBackoff(max time.Duration) Option {
+	return func(r *Reconciler) {
+		r.finalizationMaxBackoff = max
+	}
+}
+
+// WithFinalizers registers finalizers driven by IsFinalized, in addition to the default one named after the controller
+func WithFinalizers(finalizers ...Finalizer) Option {
+	return func(r *Reconciler) {
+		r.finalizers = append(r.finalizers, finalizers...)
+	}
+}
@@ -0,0 +1,104 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// OwnershipConflictReason is the condition reason reported when a child resource belongs to another owner
+	OwnershipConflictReason apis.ConditionReason = "OwnershipConflict"
+	// AdoptAnnotation opts an existing resource without controller in for adoption, when the AdoptionPolicy allows it
+	AdoptAnnotation = "toolkit/adopt"
+)
+
+// AdoptionPolicy defines which existing resources without controller can be adopted by an owner.
+// The zero value never adopts
+type AdoptionPolicy struct {
+	// Selector matches the labels of the resources which can be adopted
+	Selector labels.Selector
+	// Annotation allows the adoption of the resources annotated with AdoptAnnotation set to "true"
+	Annotation bool
+}
+
+// allows returns whether the policy allows the adoption of obj
+func (p AdoptionPolicy) allows(obj metav1.Object) bool {
+	if p.Selector != nil && !p.Selector.Empty() && p.Selector.Matches(labels.Set(obj.GetLabels())) {
+		return true
+	}
+	return p.Annotation && obj.GetAnnotations()[AdoptAnnotation] == "true"
+}
+
+// OwnershipConflictError is returned when a child resource is controlled by another owner, or
+// has no controller and can not be adopted
+type OwnershipConflictError struct {
+	// Object is the conflicting resource
+	Object types.NamespacedName
+	// Controller is the current controller of the resource, nil if it has none
+	Controller *metav1.OwnerReference
+}
+
+func (e *OwnershipConflictError) Error() string {
+	if e.Controller == nil {
+		return fmt.Sprintf("%s already exists and is not controlled by this owner", e.Object)
+	}
+	return fmt.Sprintf("%s already exists and is controlled by %s %s", e.Object, e.Controller.Kind, e.Controller.Name)
+}
+
+// setOwner sets the default namespace, the controller reference and the OwnerUIDLabel of obj, if owner is not nil.
+// Owner references can't cross namespaces nor point from cluster scoped resources to namespaced owners, such
+// resources are only tracked by label and inventory
+func (r *Reconciler) setOwner(owner, obj Resource) error {
+	if owner == nil {
+		return nil
+	}
+	if err := r.defaultNamespace(owner, obj); err != nil {
+		return err
+	}
+	if owner.GetNamespace() == "" || owner.GetNamespace() == obj.GetNamespace() {
+		if err := controllerutil.SetControllerReference(owner, obj, r.scheme); err != nil {
+			return err
+		}
+	}
+	if owner.GetUID() != "" {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[OwnerUIDLabel] = string(owner.GetUID())
+		obj.SetLabels(labels)
+	}
+	return nil
+}
+
+// checkOwnership verifies that the existing resource can be managed on behalf of owner.
+// It returns whether the resource has no controller and has to be adopted, or an ownership conflict ConditionError
+func (r *Reconciler) checkOwnership(owner, existing Resource) (bool, error) {
+	if owner == nil || IsControlledBy(owner, existing) {
+		return false, nil
+	}
+	controller := metav1.GetControllerOf(existing)
+	if controller == nil && r.adoption.allows(existing) {
+		r.loggerFor(existing).Info("Adopting resource", "owner", path.Join(owner.GetNamespace(), owner.GetName()))
+		return true, nil
+	}
+	return false, NewConditionErrorWithReason(AppliedCondition, OwnershipConflictReason, &OwnershipConflictError{
+		Object:     types.NamespacedName{Namespace: existing.GetNamespace(), Name: existing.GetName()},
+		Controller: controller,
+	})
+}
+
+// adopt sets owner as the controller of the existing resource and updates it
+func (r *Reconciler) adopt(ctx context.Context, owner, existing Resource) error {
+	return r.updateResource(ctx, existing, func(obj Resource) {
+		// the ownership has been checked before, the resource has no controller
+		_ = r.setOwner(owner, obj)
+	})
+}
@@ -0,0 +1,56 @@
+package reconciler
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestSetOwner(t *testing.T) {
+	tests := []struct {
+		name          string
+		obj           Resource
+		wantNamespace string
+		wantOwnerRef  bool
+	}{
+		{
+			name:          "namespaced child",
+			obj:           &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "child"}},
+			wantNamespace: "ns",
+			wantOwnerRef:  true,
+		},
+		{
+			name:          "child in another namespace",
+			obj:           &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "child", Namespace: "other"}},
+			wantNamespace: "other",
+			wantOwnerRef:  false,
+		},
+		{
+			name:          "cluster scoped child",
+			obj:           &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "child"}},
+			wantNamespace: "",
+			wantOwnerRef:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(nil, scheme.Scheme, nil, "test")
+			owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "ns", UID: "uid"}}
+			if err := r.setOwner(owner, tt.obj); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.obj.GetNamespace(); got != tt.wantNamespace {
+				t.Errorf("namespace = %q, want %q", got, tt.wantNamespace)
+			}
+			if got := metav1.GetControllerOf(tt.obj) != nil; got != tt.wantOwnerRef {
+				t.Errorf("owner reference set = %v, want %v", got, tt.wantOwnerRef)
+			}
+			if got := tt.obj.GetLabels()[OwnerUIDLabel]; got != "uid" {
+				t.Errorf("owner label = %q, want %q", got, "uid")
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package reconciler
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// PatchStrategy is the way CreateOrPatchResource computes the patch sent to the API server
+type PatchStrategy string
+
+const (
+	// MergePatchStrategy sends a JSON merge patch. Lists are replaced as a whole
+	MergePatchStrategy PatchStrategy = "Merge"
+	// StrategicMergePatchStrategy sends a strategic merge patch, merging lists such as containers and env by key.
+	// It is only supported by built-in types, unstructured objects fall back to MergePatchStrategy
+	StrategicMergePatchStrategy PatchStrategy = "StrategicMerge"
+	// JSONPatchStrategy sends a JSON patch generated from the field level diff
+	JSONPatchStrategy PatchStrategy = "JSONPatch"
+	// OptimisticMergePatchStrategy sends a JSON merge patch including the resource version of the found
+	// object, so that the patch fails with a conflict if the object has been modified in the meantime
+	OptimisticMergePatchStrategy PatchStrategy = "OptimisticMerge"
+)
+
+// patchStrategyFor returns the patch strategy configured for the kind of obj
+func (r *Reconciler) patchStrategyFor(obj runtime.Object) PatchStrategy {
+	if gvk, err := apiutil.GVKForObject(obj, r.scheme); err == nil {
+		if strategy, ok := r.kindPatchStrategy[gvk]; ok {
+			return strategy
+		}
+	}
+	return MergePatchStrategy
+}
+
+// newPatch returns a patch computing the changes from the given object with strategy
+func newPatch(strategy PatchStrategy, from runtime.Object) client.Patch {
+	switch strategy {
+	case StrategicMergePatchStrategy:
+		if _, ok := from.(runtime.Unstructured); !ok {
+			return &strategicMergePatch{from: from}
+		}
+	case JSONPatchStrategy:
+		return &jsonPatch{from: from}
+	case OptimisticMergePatchStrategy:
+		return &optimisticMergePatch{from: from}
+	}
+	return client.MergeFrom(from)
+}
+
+type strategicMergePatch struct {
+	from runtime.Object
+}
+
+// Type implements client.Patch
+func (p *strategicMergePatch) Type() types.PatchType {
+	return types.StrategicMergePatchType
+}
+
+// Data implements client.Patch
+func (p *strategicMergePatch) Data(obj runtime.Object) ([]byte, error) {
+	original, err := json.Marshal(p.from)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return strategicpatch.CreateTwoWayMergePatch(original, modified, obj)
+}
+
+type jsonPatch struct {
+	from runtime.Object
+}
+
+// Type implements client.Patch
+func (p *jsonPatch) Type() types.PatchType {
+	return types.JSONPatchType
+}
+
+// Data implements client.Patch
+func (p *jsonPatch) Data(obj runtime.Object) ([]byte, error) {
+	diff, err := computeDiff(p.from, obj)
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]map[string]interface{}, 0, len(diff))
+	for _, change := range diff {
+		op := map[string]interface{}{"op": change.Type, "path": change.Path}
+		if change.Type != ChangeRemove {
+			op["value"] = change.New
+		}
+		ops = append(ops, op)
+	}
+	return json.Marshal(ops)
+}
+
+type optimisticMergePatch struct {
+	from runtime.Object
+}
+
+// Type implements client.Patch
+func (p *optimisticMergePatch) Type() types.PatchType {
+	return types.MergePatchType
+}
+
+// Data implements client.Patch
+func (p *optimisticMergePatch) Data(obj runtime.Object) ([]byte, error) {
+	data, err := client.MergeFrom(p.from).Data(obj)
+	if err != nil {
+		return nil, err
+	}
+	accessor, err := meta.Accessor(p.from)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+	metadata, ok := patch["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		patch["metadata"] = metadata
+	}
+	metadata["resourceVersion"] = accessor.GetResourceVersion()
+	return json.Marshal(patch)
+}
@@ -0,0 +1,87 @@
+package reconciler
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestJSONPatchData(t *testing.T) {
+	pod := func(args ...string) *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Args: args}}}}
+	}
+	tests := []struct {
+		name string
+		from runtime.Object
+		to   runtime.Object
+		want string
+	}{
+		{
+			name: "unchanged",
+			from: &corev1.ConfigMap{Data: map[string]string{"a": "x"}},
+			to:   &corev1.ConfigMap{Data: map[string]string{"a": "x"}},
+			want: `[]`,
+		},
+		{
+			name: "replaced value",
+			from: &corev1.ConfigMap{Data: map[string]string{"a": "x"}},
+			to:   &corev1.ConfigMap{Data: map[string]string{"a": "y"}},
+			want: `[{"op":"replace","path":"/data/a","value":"y"}]`,
+		},
+		{
+			name: "key added under an existing parent",
+			from: &corev1.ConfigMap{Data: map[string]string{"a": "x"}},
+			to:   &corev1.ConfigMap{Data: map[string]string{"a": "x", "b": "y"}},
+			want: `[{"op":"add","path":"/data/b","value":"y"}]`,
+		},
+		{
+			name: "parent added",
+			from: &corev1.ConfigMap{},
+			to:   &corev1.ConfigMap{Data: map[string]string{"a": "x"}},
+			want: `[{"op":"add","path":"/data","value":{"a":"x"}}]`,
+		},
+		{
+			name: "key removed",
+			from: &corev1.ConfigMap{Data: map[string]string{"a": "x", "b": "y"}},
+			to:   &corev1.ConfigMap{Data: map[string]string{"a": "x"}},
+			want: `[{"op":"remove","path":"/data/b"}]`,
+		},
+		{
+			name: "list item changed",
+			from: pod("a", "b"),
+			to:   pod("a", "c"),
+			want: `[{"op":"replace","path":"/spec/containers/0/args/1","value":"c"}]`,
+		},
+		{
+			name: "list grown",
+			from: pod("a"),
+			to:   pod("a", "b"),
+			want: `[{"op":"replace","path":"/spec/containers/0/args","value":["a","b"]}]`,
+		},
+		{
+			name: "list shrunk",
+			from: pod("a", "b"),
+			to:   pod("a"),
+			want: `[{"op":"replace","path":"/spec/containers/0/args","value":["a"]}]`,
+		},
+		{
+			name: "escaped keys",
+			from: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"example.com/a~b": "x"}}},
+			to:   &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"example.com/a~b": "y"}}},
+			want: `[{"op":"replace","path":"/metadata/annotations/example.com~1a~0b","value":"y"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := (&jsonPatch{from: tt.from}).Data(tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Data() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package reconciler
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// DefaultPreservedFields returns the fields CreateOrUpdateResource copies by default from the live object
+// into the desired one, per kind. They are either allocated by the API server or owned by other controllers
+func DefaultPreservedFields() map[schema.GroupVersionKind][]string {
+	return map[schema.GroupVersionKind][]string{
+		corev1.SchemeGroupVersion.WithKind("Service"): {
+			".spec.clusterIP",
+			".spec.healthCheckNodePort",
+		},
+		corev1.SchemeGroupVersion.WithKind("ServiceAccount"): {
+			".secrets",
+		},
+		corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"): {
+			".spec.volumeName",
+		},
+	}
+}
+
+// preserveFields copies the preserved fields of the kind of desired from the live object.
+// Maps present in both objects (e.g. .metadata.annotations) are merged, keeping the desired values;
+// other values are overwritten with the live ones
+func (r *Reconciler) preserveFields(desired Resource, live runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(desired, r.scheme)
+	if err != nil {
+		return err
+	}
+	paths := r.preservedFields[gvk]
+	if len(paths) == 0 {
+		return nil
+	}
+
+	d, err := toUnstructured(desired)
+	if err != nil {
+		return err
+	}
+	l, err := toUnstructured(live)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		fields, err := parseFieldPath(p)
+		if err != nil {
+			return err
+		}
+		liveValue, found, err := unstructured.NestedFieldNoCopy(l, fields...)
+		if err != nil || !found {
+			continue
+		}
+		desiredValue, _, _ := unstructured.NestedFieldNoCopy(d, fields...)
+		if liveMap, ok := liveValue.(map[string]interface{}); ok {
+			if desiredMap, ok := desiredValue.(map[string]interface{}); ok {
+				for k, v := range liveMap {
+					if _, ok := desiredMap[k]; !ok {
+						desiredMap[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if err := unstructured.SetNestedField(d, liveValue, fields...); err != nil {
+			return err
+		}
+	}
+
+	if u, ok := desired.(runtime.Unstructured); ok {
+		u.SetUnstructuredContent(d)
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(d, desired)
+}
+
+// parseFieldPath splits a JSONPath like field reference, such as .spec.replicas or
+// .metadata.annotations['example.com/key'], into its fields
+func parseFieldPath(path string) ([]string, error) {
+	p := strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+	fields := []string{}
+	for len(p) > 0 {
+		switch {
+		case strings.HasPrefix(p, "['"), strings.HasPrefix(p, `["`):
+			quote := p[1:2]
+			end := strings.Index(p[2:], quote+"]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid field path %q: unterminated bracket", path)
+			}
+			fields = append(fields, p[2:2+end])
+			p = p[2+end+2:]
+		case p[0] == '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid field path %q: empty field", path)
+			}
+			fields = append(fields, p[:end])
+			p = p[end:]
+		default:
+			return nil, fmt.Errorf("invalid field path %q: expected '.' or '['", path)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid field path %q: no fields", path)
+	}
+	return fields, nil
+}
@@ -0,0 +1,138 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: ".spec.replicas", want: []string{"spec", "replicas"}},
+		{path: "{.spec.clusterIP}", want: []string{"spec", "clusterIP"}},
+		{path: ".metadata.annotations['example.com/key']", want: []string{"metadata", "annotations", "example.com/key"}},
+		{path: `.metadata.labels["app.kubernetes.io/name"]`, want: []string{"metadata", "labels", "app.kubernetes.io/name"}},
+		{path: ".metadata.annotations['a.b'].c", want: []string{"metadata", "annotations", "a.b", "c"}},
+		{path: "", wantErr: true},
+		{path: "spec.replicas", wantErr: true},
+		{path: ".spec..replicas", wantErr: true},
+		{path: ".metadata.annotations['key", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parseFieldPath(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseFieldPath() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFieldPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreserveFields(t *testing.T) {
+	r := New(nil, scheme.Scheme, nil, "test",
+		WithPreservedFields(corev1.SchemeGroupVersion.WithKind("ConfigMap"), ".metadata.annotations", ".data['live']"))
+
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"a": "desired"}},
+		Data:       map[string]string{"a": "desired"},
+	}
+	live := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"a": "live", "injected": "live"}},
+		Data:       map[string]string{"a": "live", "live": "live"},
+	}
+	if err := r.preserveFields(desired, live); err != nil {
+		t.Fatal(err)
+	}
+
+	wantAnnotations := map[string]string{"a": "desired", "injected": "live"}
+	if !reflect.DeepEqual(desired.Annotations, wantAnnotations) {
+		t.Errorf("annotations = %v, want %v", desired.Annotations, wantAnnotations)
+	}
+	wantData := map[string]string{"a": "desired", "live": "live"}
+	if !reflect.DeepEqual(desired.Data, wantData) {
+		t.Errorf("data = %v, want %v", desired.Data, wantData)
+	}
+}
+
+func TestCreateOrUpdatePreservedFields(t *testing.T) {
+	replicas := func(n int32) *int32 { return &n }
+	tests := []struct {
+		name    string
+		desired func() Resource
+		mutate  func(live Resource)
+	}{
+		{
+			name: "service cluster ip allocated",
+			desired: func() Resource {
+				return &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "svc"},
+					Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}},
+				}
+			},
+			mutate: func(live Resource) {
+				live.(*corev1.Service).Spec.ClusterIP = "10.0.0.1"
+			},
+		},
+		{
+			name: "deployment scaled",
+			desired: func() Resource {
+				return &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "deploy"},
+					Spec:       appsv1.DeploymentSpec{Replicas: replicas(1)},
+				}
+			},
+			mutate: func(live Resource) {
+				live.(*appsv1.Deployment).Spec.Replicas = replicas(5)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "ns", UID: "uid"}}
+			c := fake.NewFakeClientWithScheme(scheme.Scheme)
+			r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test",
+				WithPreservedFields(appsv1.SchemeGroupVersion.WithKind("Deployment"), ".spec.replicas"))
+
+			obj := tt.desired()
+			if res, err := r.CreateOrUpdateResource(ctx, owner, obj); err != nil || res != OperationResultCreated {
+				t.Fatalf("CreateOrUpdateResource() = %s, %v, want %s", res, err, OperationResultCreated)
+			}
+
+			// another actor sets the preserved field
+			live := tt.desired()
+			if err := c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: obj.GetName()}, live); err != nil {
+				t.Fatal(err)
+			}
+			tt.mutate(live)
+			if err := c.Update(ctx, live); err != nil {
+				t.Fatal(err)
+			}
+
+			if res, err := r.CreateOrUpdateResource(ctx, owner, tt.desired()); err != nil || res != OperationResultUnchanged {
+				t.Errorf("CreateOrUpdateResource() = %s, %v, want %s", res, err, OperationResultUnchanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package reconciler
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OwnerUIDLabel is set by the Reconciler helpers on the resources they create, along with the controller reference.
+// Its value is the UID of the owner
+const OwnerUIDLabel = "toolkit/owner-uid"
+
+// PruneOption configures PruneResources
+type PruneOption func(*pruneOptions)
+
+type pruneOptions struct {
+	kinds     []schema.GroupVersionKind
+	skipKinds map[schema.GroupVersionKind]bool
+	dryRun    bool
+}
+
+// PruneKinds adds kinds to look for owned resources, in addition to the kinds of the desired resources.
+// Kinds the owner may stop producing altogether must be listed here to be pruned
+func PruneKinds(gvks ...schema.GroupVersionKind) PruneOption {
+	return func(o *pruneOptions) {
+		o.kinds = append(o.kinds, gvks...)
+	}
+}
+
+// SkipPruneKinds excludes kinds from pruning
+func SkipPruneKinds(gvks ...schema.GroupVersionKind) PruneOption {
+	return func(o *pruneOptions) {
+		for _, gvk := range gvks {
+			o.skipKinds[gvk] = true
+		}
+	}
+}
+
+// PruneDryRun reports the resources that would be pruned without deleting them
+func PruneDryRun() PruneOption {
+	return func(o *pruneOptions) {
+		o.dryRun = true
+	}
+}
+
+// IsControlledBy returns whether obj is controlled by owner, either through the controller reference or,
+// for resources without controller reference, the OwnerUIDLabel
+func IsControlledBy(owner, obj metav1.Object) bool {
+	if ref := metav1.GetControllerOf(obj); ref != nil {
+		return ref.UID == owner.GetUID()
+	}
+	return owner.GetUID() != "" && obj.GetLabels()[OwnerUIDLabel] == string(owner.GetUID())
+}
+
+// PruneResources deletes the resources controlled by owner which are not in the desired list.
+// Resources are looked up in the namespace of the owner (all the namespaces for cluster scoped owners,
+// the whole cluster for cluster scoped kinds, see WithRESTMapper),
+// among the kinds of the desired resources and the ones passed with PruneKinds.
+// The pruned resources (or the ones that would be pruned in dry-run mode) are returned
+func (r *Reconciler) PruneResources(ctx context.Context, owner Resource, desired []Resource, opts ...PruneOption) (ResourceResults, error) {
+	o := &pruneOptions{skipKinds: map[schema.GroupVersionKind]bool{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	log := r.loggerFor(owner)
+
+	keep := map[ResourceResult]bool{}
+	kinds := []schema.GroupVersionKind{}
+	seen := map[schema.GroupVersionKind]bool{}
+	addKind := func(gvk schema.GroupVersionKind) {
+		if !seen[gvk] && !o.skipKinds[gvk] {
+			seen[gvk] = true
+			kinds = append(kinds, gvk)
+		}
+	}
+	for _, obj := range desired {
+		if err := r.defaultNamespace(owner, obj); err != nil {
+			return ResourceResults{}, err
+		}
+		if _, err := r.lookupReplacement(ctx, owner, obj); err != nil {
+			return ResourceResults{}, err
+		}
+		res := newResourceResult(r.scheme, obj, OperationResultNone)
+		keep[res] = true
+		addKind(res.GVK)
+	}
+	for _, gvk := range o.kinds {
+		addKind(gvk)
+	}
+
+	results := ResourceResults{}
+	for _, gvk := range kinds {
+		namespace := owner.GetNamespace()
+		if clusterScoped, err := r.isClusterScoped(gvk); err != nil {
+			log.Error(err, "unable to resolve the scope of kind", "gvk", gvk)
+			return results, err
+		} else if clusterScoped {
+			namespace = ""
+		}
+		existing, err := r.listKind(ctx, gvk, namespace)
+		if err != nil {
+			log.Error(err, "unable to list objects", "gvk", gvk)
+			return results, err
+		}
+		for _, obj := range existing {
+			if !IsControlledBy(owner, obj) {
+				continue
+			}
+			res := newResourceResult(r.scheme, obj, OperationResultNone)
+			res.GVK = gvk
+			if keep[res] {
+				continue
+			}
+			if o.dryRun {
+				r.loggerFor(obj).Info("Resource would be pruned (dry run)", "gvk", gvk)
+				res.Result = OperationResultDeleted
+				results = append(results, res)
+				continue
+			}
+			r.loggerFor(obj).V(1).Info("Pruning resource", "gvk", gvk)
+			res.Result, err = r.DeleteResourceIfExists(ctx, owner, obj)
+			if err != nil {
+				return results, err
+			}
+			results = append(results, res)
+		}
+	}
+	return results, nil
+}
+
+// listKind lists the resources of the given kind in namespace.
+// Kinds registered in the scheme are listed as typed objects, the others as unstructured
+func (r *Reconciler) listKind(ctx context.Context, gvk schema.GroupVersionKind, namespace string, opts ...client.ListOption) ([]Resource, error) {
+	listGVK := gvk
+	if !strings.HasSuffix(listGVK.Kind, "List") {
+		listGVK.Kind += "List"
+	}
+
+	var list runtime.Object
+	if typed, err := r.scheme.New(listGVK); err == nil {
+		list = typed
+	} else {
+		u := &unstructured.UnstructuredList{}
+		u.SetGroupVersionKind(listGVK)
+		list = u
+	}
+
+	if err := r.client.List(ctx, list, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...); err != nil {
+		return nil, err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]Resource, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(Resource); ok {
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
@@ -0,0 +1,57 @@
+package reconciler
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestIsControlledBy(t *testing.T) {
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "ns", UID: "uid"}}
+	controllerRef := func(uid types.UID) []metav1.OwnerReference {
+		controller := true
+		return []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: uid, Controller: &controller}}
+	}
+
+	tests := []struct {
+		name string
+		obj  metav1.ObjectMeta
+		want bool
+	}{
+		{
+			name: "controller reference",
+			obj:  metav1.ObjectMeta{OwnerReferences: controllerRef("uid")},
+			want: true,
+		},
+		{
+			name: "label without controller reference",
+			obj:  metav1.ObjectMeta{Labels: map[string]string{OwnerUIDLabel: "uid"}},
+			want: true,
+		},
+		{
+			name: "controlled by another owner",
+			obj:  metav1.ObjectMeta{OwnerReferences: controllerRef("other")},
+			want: false,
+		},
+		{
+			name: "controlled by another owner with label",
+			obj:  metav1.ObjectMeta{OwnerReferences: controllerRef("other"), Labels: map[string]string{OwnerUIDLabel: "uid"}},
+			want: false,
+		},
+		{
+			name: "not owned",
+			obj:  metav1.ObjectMeta{},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &corev1.ConfigMap{ObjectMeta: tt.obj}
+			if got := IsControlledBy(owner, obj); got != tt.want {
+				t.Errorf("IsControlledBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ReadyCondition is the condition type checked on kinds without a registered readiness check
+	ReadyCondition apis.ConditionType = "Ready"
+	// ProgressDeadlineExceededReason is the condition reason reported for Deployments which exceeded their progress deadline
+	ProgressDeadlineExceededReason apis.ConditionReason = "ProgressDeadlineExceeded"
+	// JobFailedReason is the condition reason reported for failed Jobs
+	JobFailedReason apis.ConditionReason = "JobFailed"
+)
+
+// ReadinessCheck reports whether obj is ready. When it is not, message explains what it is waiting for
+type ReadinessCheck func(obj runtime.Object) (ready bool, message string, err error)
+
+// ReadinessRegistry holds readiness checks keyed by kind
+type ReadinessRegistry struct {
+	checks map[schema.GroupVersionKind]ReadinessCheck
+}
+
+// NewReadinessRegistry allocates a registry with the checks for Deployments, StatefulSets, DaemonSets,
+// Jobs, PersistentVolumeClaims and Services
+func NewReadinessRegistry() *ReadinessRegistry {
+	reg := &ReadinessRegistry{checks: map[schema.GroupVersionKind]ReadinessCheck{}}
+	reg.Register(appsv1.SchemeGroupVersion.WithKind("Deployment"), deploymentReady)
+	reg.Register(appsv1.SchemeGroupVersion.WithKind("StatefulSet"), statefulSetReady)
+	reg.Register(appsv1.SchemeGroupVersion.WithKind("DaemonSet"), daemonSetReady)
+	reg.Register(batchv1.SchemeGroupVersion.WithKind("Job"), jobReady)
+	reg.Register(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), pvcReady)
+	reg.Register(corev1.SchemeGroupVersion.WithKind("Service"), serviceReady)
+	return reg
+}
+
+// Register sets the readiness check for the given kind, replacing any existing one
+func (reg *ReadinessRegistry) Register(gvk schema.GroupVersionKind, check ReadinessCheck) {
+	reg.checks[gvk] = check
+}
+
+// Check runs the readiness check registered for gvk on obj. Kinds without a registered check are
+// ready when they don't expose conditions, or when their Ready condition is true
+func (reg *ReadinessRegistry) Check(gvk schema.GroupVersionKind, obj runtime.Object) (bool, string, error) {
+	if check, ok := reg.checks[gvk]; ok {
+		return check(obj)
+	}
+	return conditionsReady(obj)
+}
+
+// CheckReadiness reports whether obj is ready, using the readiness check registered for its kind
+func (r *Reconciler) CheckReadiness(obj runtime.Object) (bool, string, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return false, "", err
+	}
+	return r.readiness.Check(gvk, obj)
+}
+
+// IsResourceReady fetches the live state of obj and reports whether it is ready
+func (r *Reconciler) IsResourceReady(ctx context.Context, obj Resource) (bool, string, error) {
+	live := obj.DeepCopyObject().(Resource)
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, live)
+	if apierrors.IsNotFound(err) {
+		return false, "not found", nil
+	} else if err != nil {
+		return false, "", err
+	}
+	return r.CheckReadiness(live)
+}
+
+// ManageReadiness checks the readiness of the children of obj. If all of them are ready, it operates as
+// ManageSuccess. Otherwise the ready status of obj is set to not ready, listing the children it waits for,
+// and the reconcile is requeued after the readiness requeue interval
+func (r *Reconciler) ManageReadiness(ctx context.Context, obj Resource, children []Resource) (reconcile.Result, error) {
+	waiting := []string{}
+	for _, child := range children {
+		ready, message, err := r.IsResourceReady(ctx, child)
+		if err != nil {
+			return r.ManageError(ctx, obj, err)
+		}
+		if !ready {
+			res := newResourceResult(r.scheme, child, OperationResultNone)
+			waiting = append(waiting, fmt.Sprintf("%s %s: %s", res.GVK.Kind, path.Join(child.GetNamespace(), child.GetName()), message))
+		}
+	}
+	if len(waiting) == 0 {
+		return r.ManageSuccess(ctx, obj)
+	}
+
+	log := r.loggerFor(obj)
+	log.V(1).Info("Waiting for resources to become ready", "resources", waiting)
+	if readyStatusAware, ok := obj.(readyStatusAware); ok {
+		status := apis.ReadyStatusOK()
+		status.Ready = false
+		status.Reason = "waiting for " + strings.Join(waiting, "; ")
+		readyStatusAware.SetReadyStatus(status)
+		if err := r.updateStatus(ctx, obj); err != nil {
+			return r.manageStatusError(obj, err)
+		}
+	}
+	return reconcile.Result{RequeueAfter: r.readinessRequeue}, nil
+}
+
+// convertTo converts a typed or unstructured obj into the typed object into
+func convertTo(obj runtime.Object, into runtime.Object) error {
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u, into)
+}
+
+func deploymentReady(obj runtime.Object) (bool, string, error) {
+	d := &appsv1.Deployment{}
+	if err := convertTo(obj, d); err != nil {
+		return false, "", err
+	}
+	if d.Status.ObservedGeneration < d.Generation {
+		return false, "rollout not observed yet", nil
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			return false, "", NewConditionErrorWithReason(ReadyCondition, ProgressDeadlineExceededReason,
+				NewNotRetriableError(fmt.Errorf("deployment %s exceeded its progress deadline", d.Name)))
+		}
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.UpdatedReplicas < replicas {
+		return false, fmt.Sprintf("%d of %d replicas updated", d.Status.UpdatedReplicas, replicas), nil
+	}
+	if d.Status.Replicas > d.Status.UpdatedReplicas {
+		return false, fmt.Sprintf("%d old replicas pending termination", d.Status.Replicas-d.Status.UpdatedReplicas), nil
+	}
+	if d.Status.AvailableReplicas < d.Status.UpdatedReplicas {
+		return false, fmt.Sprintf("%d of %d updated replicas available", d.Status.AvailableReplicas, d.Status.UpdatedReplicas), nil
+	}
+	return true, "", nil
+}
+
+func statefulSetReady(obj runtime.Object) (bool, string, error) {
+	s := &appsv1.StatefulSet{}
+	if err := convertTo(obj, s); err != nil {
+		return false, "", err
+	}
+	if s.Status.ObservedGeneration < s.Generation {
+		return false, "rollout not observed yet", nil
+	}
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	if s.Status.ReadyReplicas < replicas {
+		return false, fmt.Sprintf("%d of %d replicas ready", s.Status.ReadyReplicas, replicas), nil
+	}
+	if s.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType {
+		if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition > 0 {
+			expected := replicas - *ru.Partition
+			if s.Status.UpdatedReplicas < expected {
+				return false, fmt.Sprintf("%d of %d replicas updated", s.Status.UpdatedReplicas, expected), nil
+			}
+			return true, "", nil
+		}
+		if s.Status.UpdateRevision != s.Status.CurrentRevision {
+			return false, fmt.Sprintf("%d of %d replicas updated", s.Status.UpdatedReplicas, replicas), nil
+		}
+	}
+	return true, "", nil
+}
+
+func daemonSetReady(obj runtime.Object) (bool, string, error) {
+	d := &appsv1.DaemonSet{}
+	if err := convertTo(obj, d); err != nil {
+		return false, "", err
+	}
+	if d.Status.ObservedGeneration < d.Generation {
+		return false, "rollout not observed yet", nil
+	}
+	if d.Status.UpdatedNumberScheduled < d.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%d of %d pods updated", d.Status.UpdatedNumberScheduled, d.Status.DesiredNumberScheduled), nil
+	}
+	if d.Status.NumberAvailable < d.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%d of %d pods available", d.Status.NumberAvailable, d.Status.DesiredNumberScheduled), nil
+	}
+	return true, "", nil
+}
+
+func jobReady(obj runtime.Object) (bool, string, error) {
+	j := &batchv1.Job{}
+	if err := convertTo(obj, j); err != nil {
+		return false, "", err
+	}
+	for _, c := range j.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, "", nil
+		case batchv1.JobFailed:
+			return false, "", NewConditionErrorWithReason(ReadyCondition, JobFailedReason,
+				NewNotRetriableError(fmt.Errorf("job %s failed: %s", j.Name, c.Message)))
+		}
+	}
+	return false, fmt.Sprintf("%d pods succeeded, %d active", j.Status.Succeeded, j.Status.Active), nil
+}
+
+func pvcReady(obj runtime.Object) (bool, string, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := convertTo(obj, pvc); err != nil {
+		return false, "", err
+	}
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return false, fmt.Sprintf("claim is %s", pvc.Status.Phase), nil
+	}
+	return true, "", nil
+}
+
+func serviceReady(obj runtime.Object) (bool, string, error) {
+	svc := &corev1.Service{}
+	if err := convertTo(obj, svc); err != nil {
+		return false, "", err
+	}
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(svc.Status.LoadBalancer.Ingress) == 0 {
+		return false, "load balancer ingress not assigned", nil
+	}
+	return true, "", nil
+}
+
+// conditionsReady checks the Ready condition of objects exposing apis.Conditions, typed or unstructured.
+// Such objects are not ready until their controller reports the Ready condition
+func conditionsReady(obj runtime.Object) (bool, string, error) {
+	if getter, ok := obj.(conditionsGetter); ok {
+		c := getter.GetConditions().GetCondition(ReadyCondition)
+		if c == nil {
+			return false, "ready condition not reported yet", nil
+		}
+		if !c.IsTrue() {
+			return false, c.Message, nil
+		}
+		return true, "", nil
+	}
+
+	u, ok := obj.(runtime.Unstructured)
+	if !ok {
+		return true, "", nil
+	}
+	conditions, found, _ := unstructured.NestedSlice(u.UnstructuredContent(), "status", "conditions")
+	if !found {
+		return true, "", nil
+	}
+	for _, item := range conditions {
+		c, ok := item.(map[string]interface{})
+		if !ok || c["type"] != string(ReadyCondition) {
+			continue
+		}
+		if c["status"] != string(corev1.ConditionTrue) {
+			message, _ := c["message"].(string)
+			return false, message, nil
+		}
+		return true, "", nil
+	}
+	return false, "ready condition not reported yet", nil
+}
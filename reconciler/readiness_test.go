@@ -0,0 +1,298 @@
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// conditionsObject is a resource exposing apis.Conditions
+type conditionsObject struct {
+	corev1.ConfigMap
+	conditions apis.Conditions
+}
+
+func (o *conditionsObject) GetConditions() apis.Conditions {
+	return o.conditions
+}
+
+func TestReadinessChecks(t *testing.T) {
+	replicas := func(n int32) *int32 { return &n }
+	tests := []struct {
+		name       string
+		obj        runtime.Object
+		want       bool
+		wantReason apis.ConditionReason
+	}{
+		{
+			name: "deployment rolled out",
+			obj: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: replicas(2)},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			want: true,
+		},
+		{
+			name: "deployment generation not observed",
+			obj: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsv1.DeploymentSpec{Replicas: replicas(2)},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			want: false,
+		},
+		{
+			name: "deployment with old replicas",
+			obj: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: replicas(2)},
+				Status: appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			want: false,
+		},
+		{
+			name: "deployment exceeded its progress deadline",
+			obj: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: replicas(2)},
+				Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{{
+					Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded",
+				}}},
+			},
+			wantReason: ProgressDeadlineExceededReason,
+		},
+		{
+			name: "statefulset rolled out",
+			obj: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       replicas(3),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+				},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 3, CurrentRevision: "r2", UpdateRevision: "r2"},
+			},
+			want: true,
+		},
+		{
+			name: "statefulset rolling out",
+			obj: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       replicas(3),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+				},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "r1", UpdateRevision: "r2"},
+			},
+			want: false,
+		},
+		{
+			name: "statefulset partition updated",
+			obj: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: replicas(3),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: replicas(2)},
+					},
+				},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "r1", UpdateRevision: "r2"},
+			},
+			want: true,
+		},
+		{
+			name: "statefulset partition not updated",
+			obj: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: replicas(3),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: replicas(1)},
+					},
+				},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "r1", UpdateRevision: "r2"},
+			},
+			want: false,
+		},
+		{
+			name: "statefulset on delete with old revision",
+			obj: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       replicas(3),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+				},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, CurrentRevision: "r1", UpdateRevision: "r2"},
+			},
+			want: true,
+		},
+		{
+			name: "statefulset replicas not ready",
+			obj: &appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: replicas(3)},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			},
+			want: false,
+		},
+		{
+			name: "daemonset available",
+			obj:  &appsv1.DaemonSet{Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3}},
+			want: true,
+		},
+		{
+			name: "daemonset updating",
+			obj:  &appsv1.DaemonSet{Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 2, NumberAvailable: 3}},
+			want: false,
+		},
+		{
+			name: "daemonset pods unavailable",
+			obj:  &appsv1.DaemonSet{Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 1}},
+			want: false,
+		},
+		{
+			name: "job complete",
+			obj: &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+				Type: batchv1.JobComplete, Status: corev1.ConditionTrue,
+			}}}},
+			want: true,
+		},
+		{
+			name: "job running",
+			obj:  &batchv1.Job{Status: batchv1.JobStatus{Active: 1}},
+			want: false,
+		},
+		{
+			name: "job failed",
+			obj: &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+				Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "backoff limit exceeded",
+			}}}},
+			wantReason: JobFailedReason,
+		},
+		{
+			name: "pvc bound",
+			obj:  &corev1.PersistentVolumeClaim{Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+			want: true,
+		},
+		{
+			name: "pvc pending",
+			obj:  &corev1.PersistentVolumeClaim{Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+			want: false,
+		},
+		{
+			name: "load balancer with ingress",
+			obj: &corev1.Service{
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+				Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+					Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+				}},
+			},
+			want: true,
+		},
+		{
+			name: "load balancer without ingress",
+			obj:  &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}},
+			want: false,
+		},
+		{
+			name: "cluster ip service",
+			obj:  &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}},
+			want: true,
+		},
+	}
+	reg := NewReadinessRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gvk, err := apiutil.GVKForObject(tt.obj, scheme.Scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ready, _, err := reg.Check(gvk, tt.obj)
+			if tt.wantReason != "" {
+				conditionErr, ok := err.(*ConditionError)
+				if !ok || conditionErr.Reason != tt.wantReason {
+					t.Fatalf("Check() error = %v, want a %s ConditionError", err, tt.wantReason)
+				}
+				if _, ok := conditionErr.Err.(*notRetriableError); !ok {
+					t.Errorf("Check() error = %v, want a not retriable error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ready != tt.want {
+				t.Errorf("Check() = %v, want %v", ready, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionsReady(t *testing.T) {
+	unstructuredWith := func(conditions ...interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		if conditions != nil {
+			_ = unstructured.SetNestedSlice(u.Object, conditions, "status", "conditions")
+		}
+		return u
+	}
+	tests := []struct {
+		name string
+		obj  runtime.Object
+		want bool
+	}{
+		{
+			name: "ready condition true",
+			obj:  &conditionsObject{conditions: apis.NewConditions(apis.Condition{Type: ReadyCondition, Status: corev1.ConditionTrue})},
+			want: true,
+		},
+		{
+			name: "ready condition false",
+			obj:  &conditionsObject{conditions: apis.NewConditions(apis.Condition{Type: ReadyCondition, Status: corev1.ConditionFalse})},
+			want: false,
+		},
+		{
+			name: "ready condition not reported",
+			obj:  &conditionsObject{},
+			want: false,
+		},
+		{
+			name: "unstructured ready",
+			obj:  unstructuredWith(map[string]interface{}{"type": "Ready", "status": "True"}),
+			want: true,
+		},
+		{
+			name: "unstructured not ready",
+			obj:  unstructuredWith(map[string]interface{}{"type": "Ready", "status": "False"}),
+			want: false,
+		},
+		{
+			name: "unstructured without ready condition",
+			obj:  unstructuredWith(map[string]interface{}{"type": "Synced", "status": "True"}),
+			want: false,
+		},
+		{
+			name: "unstructured without conditions",
+			obj:  unstructuredWith(),
+			want: true,
+		},
+		{
+			name: "object without conditions",
+			obj:  &corev1.ConfigMap{},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, _, err := conditionsReady(tt.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ready != tt.want {
+				t.Errorf("conditionsReady() = %v, want %v", ready, tt.want)
+			}
+		})
+	}
+}
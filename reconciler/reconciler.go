@@ -10,12 +10,15 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -23,11 +26,35 @@ import (
 
 // Reconciler incapsulates a bunch of helpful methods usually required by custom resource reconciler
 type Reconciler struct {
+	name      string
 	client    client.Client
 	scheme    *runtime.Scheme
 	recorder  record.EventRecorder
 	finalizer string
 	log       logr.Logger
+
+	changeDetection     ChangeDetection
+	kindChangeDetection map[schema.GroupVersionKind]ChangeDetection
+	dryRun              bool
+	diffEvents          bool
+	fullUpdates         bool
+	statuses            *statusSnapshots
+	kindPatchStrategy   map[schema.GroupVersionKind]PatchStrategy
+	preservedFields     map[schema.GroupVersionKind][]string
+	immutablePolicies   map[schema.GroupVersionKind]ImmutablePolicy
+	errorClasses        map[metav1.StatusReason]ErrorClass
+	adoption            AdoptionPolicy
+	conflictBackoff     wait.Backoff
+	retries             *retryBackoff
+	events              *eventLimiter
+	readinessRequeue    time.Duration
+	notRetriableRequeue time.Duration
+	readiness           *ReadinessRegistry
+	restMapper          meta.RESTMapper
+
+	finalizers             []Finalizer
+	finalizationTimeout    time.Duration
+	finalizationMaxBackoff time.Duration
 }
 
 // GetClient returns k8s API client
@@ -62,41 +89,89 @@ func (r *Reconciler) loggerFor(obj Resource) logr.Logger {
 	return log
 }
 
-// CreateResourceIfNotExists create a resource if it doesn't already exists. If the resource exists it is left untouched and the functin does not fails
+// CreateResourceIfNotExists create a resource if it doesn't already exists. If the resource exists it is left untouched and the functin does not fails,
+// unless it is controlled by another owner (or has no controller and the AdoptionPolicy does not allow its adoption)
 // if owner is not nil, the owner field os set
 // if obj namespace is "", the namespace field of the owner is assigned
-func (r *Reconciler) CreateResourceIfNotExists(ctx context.Context, owner, obj Resource) error {
-	if owner != nil {
-		_ = controllerutil.SetControllerReference(owner, obj, r.GetScheme())
-		if obj.GetNamespace() == "" {
-			obj.SetNamespace(owner.GetNamespace())
-		}
+func (r *Reconciler) CreateResourceIfNotExists(ctx context.Context, owner, obj Resource) (OperationResult, error) {
+	log := r.loggerFor(obj)
+	if err := r.setOwner(owner, obj); err != nil {
+		log.Error(err, "unable to set owner")
+		return OperationResultNone, err
 	}
 
-	log := r.loggerFor(obj)
 	log.V(2).Info("Creating resource if does not exist")
 
-	err := r.GetClient().Create(ctx, obj)
+	err := r.create(ctx, obj)
 	if err != nil && apierrors.IsAlreadyExists(err) {
 		log.V(2).Info("Resource already exists")
+		if owner == nil {
+			return OperationResultUnchanged, nil
+		}
+		existing := obj.DeepCopyObject().(Resource)
+		if err := r.GetClient().Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing); err != nil {
+			log.Error(err, "unable to lookup object")
+			return OperationResultNone, err
+		}
+		adopt, err := r.checkOwnership(owner, existing)
+		if err != nil {
+			return OperationResultNone, err
+		}
+		if !adopt {
+			return OperationResultUnchanged, nil
+		}
+		if err := r.adopt(ctx, owner, existing); err != nil {
+			log.Error(err, "unable to adopt object")
+			return OperationResultNone, err
+		}
+		r.recordInventory(owner, existing)
+		return OperationResultAdopted, nil
 	} else if err != nil {
 		log.Error(err, "unable to create object")
-		return err
+		return OperationResultNone, err
 	}
-	return nil
+	r.recordInventory(owner, obj)
+	return OperationResultCreated, nil
 }
 
-// CreateOrUpdateResource creates a resource if it doesn't exist, and updates (overwrites it), if it exist
+// CreateOrUpdateResource creates a resource if it doesn't exist, and updates (overwrites it), if it exist.
+// The update is skipped when the found resource already matches obj, according to the configured ChangeDetection.
+// The preserved fields of the kind (see DefaultPreservedFields and WithPreservedFields) are copied from the found resource
 // if owner is not nil, the owner field os set
 // if obj namespace is "", the namespace field of the owner is assigned
-func (r *Reconciler) CreateOrUpdateResource(ctx context.Context, owner, obj Resource) error {
-	if owner != nil {
-		_ = controllerutil.SetControllerReference(owner, obj, r.GetScheme())
-		if obj.GetNamespace() == "" {
-			obj.SetNamespace(owner.GetNamespace())
-		}
+// Update conflicts are retried with the configured backoff, re-fetching the found resource.
+// Updates rejected because of immutable fields are handled according to the ImmutablePolicy of the kind
+func (r *Reconciler) CreateOrUpdateResource(ctx context.Context, owner, obj Resource) (OperationResult, error) {
+	if err := r.setOwner(owner, obj); err != nil {
+		r.loggerFor(obj).Error(err, "unable to set owner")
+		return OperationResultNone, err
+	}
+	if err := r.resolveReplacement(ctx, owner, obj); err != nil {
+		r.loggerFor(obj).Error(err, "unable to resolve replacement")
+		return OperationResultNone, err
+	}
+	// the hash covers the desired state only, not the preserved fields copied from the found resource
+	if _, err := r.setLastAppliedHash(obj); err != nil {
+		r.loggerFor(obj).Error(err, "unable to hash object")
+		return OperationResultNone, err
+	}
+
+	var result OperationResult
+	err := r.retryOnConflict(func() (err error) {
+		result, err = r.createOrUpdate(ctx, owner, obj)
+		return err
+	})
+	if err != nil && isImmutableFieldError(err) {
+		result, err = r.manageImmutableChange(ctx, owner, obj, err)
 	}
+	if err == nil {
+		r.recordInventory(owner, obj)
+	}
+	return result, err
+}
 
+// createOrUpdate performs a single attempt of CreateOrUpdateResource
+func (r *Reconciler) createOrUpdate(ctx context.Context, owner, obj Resource) (OperationResult, error) {
 	log := r.loggerFor(obj)
 
 	obj2 := obj.DeepCopyObject()
@@ -108,42 +183,86 @@ func (r *Reconciler) CreateOrUpdateResource(ctx context.Context, owner, obj Reso
 
 	if apierrors.IsNotFound(err) {
 		log.V(2).Info("Creating resource")
-		err = r.GetClient().Create(ctx, obj)
+		err = r.create(ctx, obj)
 		if err != nil {
 			log.Error(err, "unable to create object")
-			return err
+			return OperationResultNone, err
 		}
-		return nil
+		return OperationResultCreated, nil
 	}
 	if err == nil {
 		obj3, ok := obj2.(metav1.Object)
 		if !ok {
-			return errors.New("unable to convert to metav1.Object")
+			return OperationResultNone, errors.New("unable to convert to metav1.Object")
 		}
 		obj.SetResourceVersion(obj3.GetResourceVersion())
-		log.V(2).Info("Updating resource")
-		err = r.GetClient().Update(ctx, obj)
+
+		// the update sets the owner references of obj, adopting the found resource if allowed
+		if _, err := r.checkOwnership(owner, obj3.(Resource)); err != nil {
+			return OperationResultNone, err
+		}
+
+		if err := r.preserveFields(obj, obj2); err != nil {
+			log.Error(err, "unable to preserve fields")
+			return OperationResultNone, err
+		}
+
+		changed, err := r.needsUpdate(obj, obj2)
 		if err != nil {
+			log.Error(err, "unable to compare objects")
+			return OperationResultNone, err
+		}
+		if !changed {
+			log.V(2).Info("Resource is up to date")
+			return OperationResultUnchanged, nil
+		}
+
+		log.V(2).Info("Updating resource")
+		err = r.update(ctx, obj2, obj)
+		if err != nil && apierrors.IsConflict(err) {
+			log.V(1).Info("Conflict updating object")
+			return OperationResultNone, err
+		} else if err != nil {
 			log.Error(err, "unable to update object")
-			return err
+			return OperationResultNone, err
 		}
-		return nil
+		r.reportChange(ctx, owner, OperationResultUpdated, obj2, obj)
+		return OperationResultUpdated, nil
 	}
 	r.log.Error(err, "unable to lookup object", "object", obj)
-	return err
+	return OperationResultNone, err
 }
 
-// CreateOrPatchResource creates a resource if it doesn't exist, and patches, if it exist
+// CreateOrPatchResource creates a resource if it doesn't exist, and patches, if it exist.
+// If fpatch leaves the found object untouched, no patch is sent and OperationResultUnchanged is returned
 // if owner is not nil, the owner field os se
 // if obj namespace is "", the namespace field of the owner is assigned
-func (r *Reconciler) CreateOrPatchResource(ctx context.Context, owner, obj Resource, fpatch func(objFound, objNew runtime.Object) error) error {
-	if owner != nil {
-		_ = controllerutil.SetControllerReference(owner, obj, r.GetScheme())
-		if obj.GetNamespace() == "" {
-			obj.SetNamespace(owner.GetNamespace())
-		}
+// Patch conflicts are retried with the configured backoff, re-fetching the found resource and calling fpatch again.
+// The patch strategy configured for the kind of obj is used, MergePatchStrategy by default
+func (r *Reconciler) CreateOrPatchResource(ctx context.Context, owner, obj Resource, fpatch func(objFound, objNew runtime.Object) error) (OperationResult, error) {
+	return r.CreateOrPatchResourceWithStrategy(ctx, owner, obj, r.patchStrategyFor(obj), fpatch)
+}
+
+// CreateOrPatchResourceWithStrategy operates as CreateOrPatchResource, using the given patch strategy
+func (r *Reconciler) CreateOrPatchResourceWithStrategy(ctx context.Context, owner, obj Resource, strategy PatchStrategy, fpatch func(objFound, objNew runtime.Object) error) (OperationResult, error) {
+	if err := r.setOwner(owner, obj); err != nil {
+		r.loggerFor(obj).Error(err, "unable to set owner")
+		return OperationResultNone, err
+	}
+
+	var result OperationResult
+	err := r.retryOnConflict(func() (err error) {
+		result, err = r.createOrPatch(ctx, owner, obj, strategy, fpatch)
+		return err
+	})
+	if err == nil {
+		r.recordInventory(owner, obj)
 	}
+	return result, err
+}
 
+// createOrPatch performs a single attempt of CreateOrPatchResource
+func (r *Reconciler) createOrPatch(ctx context.Context, owner, obj Resource, strategy PatchStrategy, fpatch func(objFound, objNew runtime.Object) error) (OperationResult, error) {
 	found := obj.DeepCopyObject().(Resource)
 
 	err := r.GetClient().Get(ctx, types.NamespacedName{
@@ -154,100 +273,179 @@ func (r *Reconciler) CreateOrPatchResource(ctx context.Context, owner, obj Resou
 	if apierrors.IsNotFound(err) {
 		log := r.loggerFor(obj)
 		log.V(2).Info("Creating resource")
-		err = r.GetClient().Create(ctx, obj)
+		err = r.create(ctx, obj)
 		if err != nil {
 			log.Error(err, "unable to create object")
-			return err
+			return OperationResultNone, err
 		}
-		return nil
+		return OperationResultCreated, nil
 	}
 
 	if err == nil {
 		log := r.loggerFor(found)
 
-		patch := client.MergeFrom(found.DeepCopyObject())
+		adopt, err := r.checkOwnership(owner, found)
+		if err != nil {
+			return OperationResultNone, err
+		}
+
+		before := found.DeepCopyObject()
+		patch := newPatch(strategy, before)
 		if err := fpatch(found, obj); err != nil {
 			log.Error(err, "failed to modify object")
-			return err
+			return OperationResultNone, err
+		}
+		if adopt {
+			if err := r.setOwner(owner, found); err != nil {
+				log.Error(err, "unable to set owner")
+				return OperationResultNone, err
+			}
 		}
 
-		log.V(2).Info("Patching resource")
-		err = r.GetClient().Patch(ctx, found, patch)
+		diff, err := computeDiff(before, found)
 		if err != nil {
+			log.Error(err, "unable to compute patch")
+			return OperationResultNone, err
+		}
+		if len(diff) == 0 {
+			log.V(2).Info("Resource is up to date")
+			return OperationResultUnchanged, nil
+		}
+
+		log.V(2).Info("Patching resource", "strategy", strategy)
+		err = r.patch(ctx, before, found, patch)
+		if err != nil && apierrors.IsConflict(err) {
+			log.V(1).Info("Conflict patching object")
+			return OperationResultNone, err
+		} else if err != nil {
 			log.Error(err, "unable to patch object")
-			return err
+			return OperationResultNone, err
 		}
-		return nil
+		r.reportChange(ctx, owner, OperationResultPatched, before, found)
+		return OperationResultPatched, nil
 	}
 
 	r.log.Error(err, "unable to lookup object", "object", obj)
-	return err
+	return OperationResultNone, err
 }
 
-// DeleteResourceIfExists deletes an existing resource. It doesn't fail if the resource does not exist
-func (r *Reconciler) DeleteResourceIfExists(obj Resource) error {
+// DeleteResourceIfExists deletes an existing resource. It doesn't fail if the resource does not exist.
+// If owner is not nil, the resource is deleted only if it is controlled by owner, otherwise an ownership
+// conflict ConditionError is returned. The deletion is guarded by a precondition on the UID of the found
+// resource, unless preconditions are passed in opts. The propagation policy can be set with client.PropagationPolicy
+func (r *Reconciler) DeleteResourceIfExists(ctx context.Context, owner, obj Resource, opts ...client.DeleteOption) (OperationResult, error) {
 	log := r.loggerFor(obj)
+
+	found := obj.DeepCopyObject().(Resource)
+	err := r.GetClient().Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, found)
+	if err != nil && apierrors.IsNotFound(err) {
+		r.forgetInventory(owner, obj)
+		return OperationResultNotFound, nil
+	} else if err != nil {
+		log.Error(err, "unable to lookup object")
+		return OperationResultNone, err
+	}
+
+	if owner != nil && !IsControlledBy(owner, found) {
+		log.Info("Refusing to delete resource not controlled by the owner")
+		return OperationResultNone, NewConditionErrorWithReason(AppliedCondition, OwnershipConflictReason, &OwnershipConflictError{
+			Object:     types.NamespacedName{Namespace: found.GetNamespace(), Name: found.GetName()},
+			Controller: metav1.GetControllerOf(found),
+		})
+	}
+
+	uid := found.GetUID()
+	opts = append([]client.DeleteOption{client.Preconditions{UID: &uid}}, opts...)
+
 	log.V(2).Info("Removing resource", "object", obj)
-	err := r.GetClient().Delete(context.TODO(), obj)
-	if err != nil && !apierrors.IsNotFound(err) {
+	err = r.delete(ctx, found, opts...)
+	if err != nil && apierrors.IsNotFound(err) {
+		r.forgetInventory(owner, obj)
+		return OperationResultNotFound, nil
+	} else if err != nil {
 		log.Error(err, "unable to delete object ", "object", obj)
-		return err
+		return OperationResultNone, err
 	}
-	return nil
+	r.forgetInventory(owner, obj)
+	return OperationResultDeleted, nil
 }
 
-// DeleteResourcesIfExist operates like DeleteResources, but on an arrays of resources
-func (r *Reconciler) DeleteResourcesIfExist(objs []Resource) error {
+// DeleteResourcesIfExist operates like DeleteResourceIfExists, but on an arrays of resources.
+// The results of the resources processed before a failure are returned along with the error
+func (r *Reconciler) DeleteResourcesIfExist(ctx context.Context, owner Resource, objs []Resource, opts ...client.DeleteOption) (ResourceResults, error) {
+	results := make(ResourceResults, 0, len(objs))
 	for _, obj := range objs {
-		err := r.DeleteResourceIfExists(obj)
+		res, err := r.DeleteResourceIfExists(ctx, owner, obj, opts...)
 		if err != nil {
-			return err
+			return results, err
 		}
+		results = append(results, newResourceResult(r.scheme, obj, res))
 	}
-	return nil
+	return results, nil
 }
 
-// CreateResourcesIfNotExist operates as CreateResourceIfNotExists, but on an array of resources
-func (r *Reconciler) CreateResourcesIfNotExist(ctx context.Context, owner Resource, objs []Resource) error {
+// CreateResourcesIfNotExist operates as CreateResourceIfNotExists, but on an array of resources.
+// The results of the resources processed before a failure are returned along with the error
+func (r *Reconciler) CreateResourcesIfNotExist(ctx context.Context, owner Resource, objs []Resource) (ResourceResults, error) {
+	results := make(ResourceResults, 0, len(objs))
 	for _, obj := range objs {
-		err := r.CreateResourceIfNotExists(ctx, owner, obj)
+		res, err := r.CreateResourceIfNotExists(ctx, owner, obj)
 		if err != nil {
-			return err
+			return results, err
 		}
+		results = append(results, newResourceResult(r.scheme, obj, res))
 	}
-	return nil
+	return results, nil
 }
 
-// CreateOrUpdateResources operates as CreateOrUpdate, but on an array of resources
-func (r *Reconciler) CreateOrUpdateResources(ctx context.Context, owner Resource, objs []Resource) error {
+// CreateOrUpdateResources operates as CreateOrUpdate, but on an array of resources.
+// The results of the resources processed before a failure are returned along with the error
+func (r *Reconciler) CreateOrUpdateResources(ctx context.Context, owner Resource, objs []Resource) (ResourceResults, error) {
+	results := make(ResourceResults, 0, len(objs))
 	for _, obj := range objs {
-		err := r.CreateOrUpdateResource(ctx, owner, obj)
+		res, err := r.CreateOrUpdateResource(ctx, owner, obj)
 		if err != nil {
-			return err
+			return results, err
 		}
+		results = append(results, newResourceResult(r.scheme, obj, res))
 	}
-	return nil
+	return results, nil
 }
 
 // ManageError will take care of the following:
-// 1. generate a warning event attched to the passed object
+// 1. generate a warning event attched to the passed object, with the reason of the error, unless the error only
+// requires a quiet requeue (conflicts...). Events are deduplicated and rate limited per object and reason
+// (see WithEventDeduplication and WithEventRateLimit)
 // 2. set the status to failed if the object implements the readyStatusAware interface
-// 3. return empty reconcile result with the passed error
+// 3. return a reconcile result depending on the class of the error: toolkit errors are classified by type and
+// Kubernetes API errors by reason (see DefaultErrorClasses and WithErrorClass). Other errors are returned
+// An AggregateError sets the conditions of all its ConditionErrors and is classified after its members
 func (r *Reconciler) ManageError(ctx context.Context, obj Resource, err error) (reconcile.Result, error) {
-	r.recorder.Event(obj, "Warning", "ProcessingError", err.Error())
 	log := r.loggerFor(obj)
+	class, retryAfter := r.classifyError(err)
+	if class == ErrorClassRequeue {
+		// Conflicts are requeued through the rate limiter, leaving the status untouched
+		log.Info("Conflict. Requeue", "Error", err.Error())
+		return reconcile.Result{Requeue: true}, nil
+	}
+	r.recordError(obj, err)
 
-	statusChanged := false
+	// a new generation may have fixed a terminal error
+	statusChanged := clearStalled(obj)
 
-	// set condition if the error is of ConditionError type
+	// set conditions if the error is of ConditionError type or aggregates them
 	if conditionsStatusAware, ok := obj.(conditionsStatusAware); ok {
-		var conditionErr *ConditionError
-		if errors.As(err, &conditionErr) {
+		conditionErrs := conditionErrors(err)
+		for _, conditionErr := range conditionErrs {
 			c := conditionErr.Condition()
 			log.V(1).Info("Setting status condition", "Condition", c)
-			statusChanged = conditionsStatusAware.SetCondition(c)
-			// unwrap error
-			err = conditionErr.Err
+			if conditionsStatusAware.SetCondition(c) {
+				statusChanged = true
+			}
+		}
+		// unwrap error
+		if _, aggregate := asAggregate(err); !aggregate && len(conditionErrs) == 1 {
+			err = conditionErrs[0].Err
 		}
 	}
 	// Set readiness state if supported
@@ -256,97 +454,120 @@ func (r *Reconciler) ManageError(ctx context.Context, obj Resource, err error) (
 		readyStatusAware.SetReadyStatus(apis.FailedReadyStatus(err))
 		statusChanged = true
 	}
+	// Mark the resource as stalled on terminal errors
+	if class == ErrorClassTerminal {
+		if conditionsStatusAware, ok := obj.(conditionsStatusAware); ok {
+			if conditionsStatusAware.SetCondition(stalledCondition(obj, err)) {
+				statusChanged = true
+			}
+		}
+	}
+	// Track the attempts of errors retried with backoff
+	if class == ErrorClassBackoff {
+		var attempt int
+		attempt, retryAfter = r.retries.next(obj.GetUID())
+		if conditionsStatusAware, ok := obj.(conditionsStatusAware); ok {
+			if conditionsStatusAware.SetCondition(retryingCondition(attempt, retryAfter, err)) {
+				statusChanged = true
+			}
+		}
+	}
 
 	// Update status if changed
 	if statusChanged {
-		if err := r.client.Status().Update(context.TODO(), obj); err != nil {
-			log.Error(err, "Unable to update status")
-			return reconcile.Result{RequeueAfter: time.Second}, nil
+		if err := r.updateStatus(ctx, obj); err != nil {
+			return r.manageStatusError(obj, err)
 		}
 	}
 
-	// For not retriable errors set RequeueAfter to 6 hours (avg time of Event lifetime)
-	var notRetriableErr *notRetriableError
-	if errors.As(err, &notRetriableErr) {
-		log.Error(notRetriableErr.issue, "Not retriable error")
-		return reconcile.Result{RequeueAfter: 6 * time.Hour}, nil
-	}
-
-	// For retriable error, we have a recommended time interval to retry reconcile later.
-	var retriableErr *retriableError
-	if errors.As(err, &retriableErr) {
-		log.Info("Retriable error. Mute an issue and reque", "Error", retriableErr.Error(), "RequeAfter", retriableErr.retryAfter)
-		return reconcile.Result{RequeueAfter: retriableErr.retryAfter}, nil
+	switch class {
+	case ErrorClassTerminal:
+		log.Error(err, "Terminal error. Not requeued until the generation changes")
+		return reconcile.Result{}, nil
+	case ErrorClassNotRetriable:
+		// For not retriable errors set RequeueAfter to 6 hours by default (avg time of Event lifetime)
+		log.Error(err, "Not retriable error")
+		return reconcile.Result{RequeueAfter: r.notRetriableRequeue}, nil
+	case ErrorClassRetriable:
+		// For retriable error, we have a recommended time interval to retry reconcile later.
+		log.Info("Retriable error. Mute an issue and reque", "Error", err.Error(), "RequeAfter", retryAfter)
+		return reconcile.Result{RequeueAfter: retryAfter}, nil
+	case ErrorClassBackoff:
+		log.Info("Retriable error. Back off and reque", "Error", err.Error(), "RequeAfter", retryAfter)
+		return reconcile.Result{RequeueAfter: retryAfter}, nil
 	}
 
 	return reconcile.Result{Requeue: true}, err
 }
 
-// ManageSuccess will update the status of the CR and return a successful reconcile result
+// ManageSuccess will update the status of the CR and return a successful reconcile result.
+// The backoff state of the CR is cleared, as well as its Stalled condition if its generation changed
 func (r *Reconciler) ManageSuccess(ctx context.Context, obj Resource) (reconcile.Result, error) {
+	r.resetBackoff(obj)
+	clearStalled(obj)
 	if readyStatusAware, ok := obj.(readyStatusAware); ok {
 		readyStatusAware.SetReadyStatus(apis.ReadyStatusOK())
 	}
-	if err := r.client.Status().Update(context.TODO(), obj); err != nil {
-		r.loggerFor(obj).Error(err, "Unable to update status")
-		return reconcile.Result{RequeueAfter: time.Second}, nil
+	if err := r.updateStatus(ctx, obj); err != nil {
+		return r.manageStatusError(obj, err)
 	}
 	return reconcile.Result{}, nil
 }
 
-// IsFinalized setup finalizer and updates the resource
-func (r *Reconciler) IsFinalized(ctx context.Context, obj Resource, clean func() error) (bool, error) {
-	log := r.loggerFor(obj).WithValues("finalizer", r.finalizer)
-
-	if IsBeingDeleted(obj) {
-		if !HasFinalizer(obj, r.finalizer) {
-			return false, nil
-		}
-
-		if err := clean(); err != nil {
-			log.Error(err, "Unable to cleanup finalizer")
-			return false, err
-		}
-
-		log.V(2).Info("Removing finalizer")
-		RemoveFinalizer(obj, r.finalizer)
-		err := r.GetClient().Update(ctx, obj)
-		if err != nil {
-			log.Error(err, "Unable to remove finalizer")
-		}
-		return false, err
-	}
-
-	if !HasFinalizer(obj, r.finalizer) {
-		r.log.V(2).Info("Adding finalizer")
-		AddFinalizer(obj, r.finalizer)
-		err := r.GetClient().Update(ctx, obj)
-		if err != nil {
-			log.Error(err, "Unable to add finalizer")
-		}
-		return false, err
+// manageStatusError returns the reconcile result for a failed status update
+func (r *Reconciler) manageStatusError(obj Resource, err error) (reconcile.Result, error) {
+	log := r.loggerFor(obj)
+	var conflictErr *ConflictError
+	if errors.As(err, &conflictErr) {
+		log.Info("Conflict retries exhausted updating status. Requeue", "Error", conflictErr.Error())
+		return reconcile.Result{Requeue: true}, nil
 	}
-
-	return true, nil
+	log.Error(err, "Unable to update status")
+	return reconcile.Result{RequeueAfter: time.Second}, nil
 }
 
 // NewWithManager allocates new base reconciler using manager
-func NewWithManager(mgr manager.Manager, controllerName string) *Reconciler {
+func NewWithManager(mgr manager.Manager, controllerName string, opts ...Option) *Reconciler {
 	return New(
 		mgr.GetClient(),
 		mgr.GetScheme(),
 		mgr.GetEventRecorderFor(controllerName),
 		controllerName,
+		append([]Option{WithRESTMapper(mgr.GetRESTMapper())}, opts...)...,
 	)
 }
 
 // New allocates new base reconciler
-func New(client client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, controllerName string) *Reconciler {
-	return &Reconciler{
+func New(client client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, controllerName string, opts ...Option) *Reconciler {
+	r := &Reconciler{
+		name:      controllerName,
 		client:    client,
 		scheme:    scheme,
 		recorder:  recorder,
 		finalizer: controllerName,
 		log:       logf.Log.WithName(controllerName),
+
+		changeDetection:     ChangeDetectionSemantic,
+		kindChangeDetection: map[schema.GroupVersionKind]ChangeDetection{},
+		kindPatchStrategy:   map[schema.GroupVersionKind]PatchStrategy{},
+		preservedFields:     DefaultPreservedFields(),
+		immutablePolicies:   map[schema.GroupVersionKind]ImmutablePolicy{},
+		errorClasses:        DefaultErrorClasses(),
+		conflictBackoff:     retry.DefaultBackoff,
+		retries:             newRetryBackoff(DefaultRetryBackoff),
+		statuses:            newStatusSnapshots(),
+		events:              newEventLimiter(10*time.Minute, 1.0/60, 5),
+		readinessRequeue:    10 * time.Second,
+		notRetriableRequeue: 6 * time.Hour,
+		readiness:           NewReadinessRegistry(),
+
+		finalizationMaxBackoff: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.restMapper == nil && scheme != nil {
+		r.restMapper = newSchemeRESTMapper(scheme)
 	}
+	return r
 }
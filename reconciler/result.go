@@ -0,0 +1,92 @@
+package reconciler
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// OperationResult is the action taken by a reconciler helper on a resource
+type OperationResult string
+
+const (
+	// OperationResultNone means no operation has been performed, typically because of an error
+	OperationResultNone OperationResult = ""
+	// OperationResultCreated means the resource has been created
+	OperationResultCreated OperationResult = "Created"
+	// OperationResultUpdated means the resource has been updated
+	OperationResultUpdated OperationResult = "Updated"
+	// OperationResultPatched means the resource has been patched
+	OperationResultPatched OperationResult = "Patched"
+	// OperationResultUnchanged means the resource already exists and has been left untouched
+	OperationResultUnchanged OperationResult = "Unchanged"
+	// OperationResultDeleted means the resource has been deleted
+	OperationResultDeleted OperationResult = "Deleted"
+	// OperationResultNotFound means the resource to delete does not exist
+	OperationResultNotFound OperationResult = "NotFound"
+	// OperationResultRecreated means the resource has been deleted and created again
+	OperationResultRecreated OperationResult = "Recreated"
+	// OperationResultReplaced means the resource has been replaced by a new one with a different name
+	OperationResultReplaced OperationResult = "Replaced"
+	// OperationResultAdopted means the existing resource had no controller and has been adopted by the owner
+	OperationResultAdopted OperationResult = "Adopted"
+)
+
+// Changed returns whether the operation modified the cluster state
+func (o OperationResult) Changed() bool {
+	switch o {
+	case OperationResultCreated, OperationResultUpdated, OperationResultPatched, OperationResultDeleted,
+		OperationResultRecreated, OperationResultReplaced, OperationResultAdopted:
+		return true
+	}
+	return false
+}
+
+// ResourceResult is the operation result for a single resource of a batch
+type ResourceResult struct {
+	GVK            schema.GroupVersionKind
+	NamespacedName types.NamespacedName
+	Result         OperationResult
+}
+
+// ResourceResults is a list of per resource operation results
+type ResourceResults []ResourceResult
+
+// Changed returns whether any of the operations modified the cluster state
+func (results ResourceResults) Changed() bool {
+	for _, res := range results {
+		if res.Result.Changed() {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the operation result for the resource with the given kind and name.
+// If the resource is not in the list, OperationResultNone is returned
+func (results ResourceResults) Get(gvk schema.GroupVersionKind, key types.NamespacedName) OperationResult {
+	for _, res := range results {
+		if res.GVK == gvk && res.NamespacedName == key {
+			return res.Result
+		}
+	}
+	return OperationResultNone
+}
+
+// newResourceResult makes a batch entry for obj
+func newResourceResult(scheme *runtime.Scheme, obj Resource, result OperationResult) ResourceResult {
+	// unregistered types are reported with whatever kind the object carries
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		gvk = obj.GetObjectKind().GroupVersionKind()
+	}
+	return ResourceResult{
+		GVK: gvk,
+		NamespacedName: types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		},
+		Result: result,
+	}
+}
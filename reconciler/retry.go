@@ -0,0 +1,137 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// retryOnConflict runs fn until it succeeds, fails with an error other than Conflict or the conflict backoff
+// is exhausted. In the latter case a ConflictError is returned
+func (r *Reconciler) retryOnConflict(fn func() error) error {
+	err := retry.RetryOnConflict(r.conflictBackoff, fn)
+	if err != nil && apierrors.IsConflict(err) {
+		return &ConflictError{Err: err}
+	}
+	return err
+}
+
+// updateResource applies mutate to obj and updates it. On conflict obj is re-fetched and mutated again
+func (r *Reconciler) updateResource(ctx context.Context, obj Resource, mutate func(obj Resource)) error {
+	refetch := false
+	return r.retryOnConflict(func() error {
+		if refetch {
+			if err := r.client.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, obj); err != nil {
+				return err
+			}
+		}
+		refetch = true
+		before := obj.DeepCopyObject()
+		mutate(obj)
+		return r.update(ctx, before, obj)
+	})
+}
+
+// updateFinalizers applies mutate to the finalizers of obj and sends a merge patch replacing only
+// metadata.finalizers, guarded by the resource version so that finalizers added concurrently are not dropped.
+// On conflict obj is re-fetched and mutated again. With full updates enabled obj is updated as a whole
+func (r *Reconciler) updateFinalizers(ctx context.Context, obj Resource, mutate func(obj Resource)) error {
+	if r.fullUpdates {
+		return r.updateResource(ctx, obj, mutate)
+	}
+	refetch := false
+	return r.retryOnConflict(func() error {
+		if refetch {
+			if err := r.client.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, obj); err != nil {
+				return err
+			}
+		}
+		refetch = true
+		before := obj.DeepCopyObject().(Resource)
+		mutate(obj)
+		if reflect.DeepEqual(before.GetFinalizers(), obj.GetFinalizers()) {
+			return nil
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"finalizers":      obj.GetFinalizers(),
+				"resourceVersion": obj.GetResourceVersion(),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if err := r.patch(ctx, before, obj, client.ConstantPatch(types.MergePatchType, data)); err != nil {
+			return err
+		}
+		if r.IsDryRun(ctx) {
+			return nil
+		}
+		// the patched object holds the status at its new resource version
+		return r.statuses.record(obj)
+	})
+}
+
+// updateStatus writes the status of obj with a merge patch computed from its status as last written. With full
+// updates enabled the status is updated instead; on conflict the resource version is refreshed and the
+// status of obj is written again
+func (r *Reconciler) updateStatus(ctx context.Context, obj Resource) error {
+	if !r.fullUpdates {
+		return r.patchStatus(ctx, obj)
+	}
+	refetch := false
+	return r.retryOnConflict(func() error {
+		if refetch {
+			latest := obj.DeepCopyObject().(Resource)
+			if err := r.client.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, latest); err != nil {
+				return err
+			}
+			obj.SetResourceVersion(latest.GetResourceVersion())
+		}
+		refetch = true
+		return r.writeStatus(ctx, obj)
+	})
+}
+
+// patchStatus sends a merge patch holding the differences between the status of obj and its status at the same
+// resource version, as returned by the last write of the Reconciler. The write is skipped when there are none.
+// If that status is unknown, e.g. because obj has been changed by someone else since, the whole status is sent
+func (r *Reconciler) patchStatus(ctx context.Context, obj Resource) error {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return err
+	}
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	after := &unstructured.Unstructured{Object: u}
+	after.SetGroupVersionKind(gvk)
+	// obj as it was last written, differing in its status only
+	before := after.DeepCopy()
+	delete(before.Object, "status")
+	if status, ok := r.statuses.get(obj); ok && status != nil {
+		before.Object["status"] = status
+	}
+	data, err := client.MergeFrom(before).Data(after)
+	if err != nil {
+		return err
+	}
+	if string(data) == "{}" {
+		return nil
+	}
+	if err := r.writeStatusPatch(ctx, before, obj, client.ConstantPatch(types.MergePatchType, data)); err != nil {
+		return err
+	}
+	if r.IsDryRun(ctx) {
+		return nil
+	}
+	return r.statuses.record(obj)
+}
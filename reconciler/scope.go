@@ -0,0 +1,76 @@
+package reconciler
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// clusterScopedKinds lists the cluster scoped kinds of the built-in API groups
+var clusterScopedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Namespace"}:                                                  true,
+	{Group: "", Kind: "Node"}:                                                       true,
+	{Group: "", Kind: "PersistentVolume"}:                                           true,
+	{Group: "", Kind: "ComponentStatus"}:                                            true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                 true,
+	{Group: "storage.k8s.io", Kind: "VolumeAttachment"}:                             true,
+	{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                    true,
+	{Group: "storage.k8s.io", Kind: "CSINode"}:                                      true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+	{Group: "policy", Kind: "PodSecurityPolicy"}:                                    true,
+	{Group: "extensions", Kind: "PodSecurityPolicy"}:                                true,
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}:               true,
+	{Group: "node.k8s.io", Kind: "RuntimeClass"}:                                    true,
+}
+
+// newSchemeRESTMapper returns a RESTMapper knowing the kinds registered in scheme. They are namespaced,
+// except the cluster scoped built-in kinds. It is used by New when no RESTMapper is set with WithRESTMapper
+func newSchemeRESTMapper(scheme *runtime.Scheme) meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(scheme.PrioritizedVersionsAllGroups())
+	for gvk := range scheme.AllKnownTypes() {
+		if clusterScopedKinds[gvk.GroupKind()] {
+			mapper.Add(gvk, meta.RESTScopeRoot)
+		} else {
+			mapper.Add(gvk, meta.RESTScopeNamespace)
+		}
+	}
+	return mapper
+}
+
+// isClusterScoped returns whether gvk is a cluster scoped kind, according to the RESTMapper of the Reconciler.
+// Kinds unknown to the RESTMapper are reported as errors
+func (r *Reconciler) isClusterScoped(gvk schema.GroupVersionKind) (bool, error) {
+	mapping, err := r.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, fmt.Errorf("unable to resolve the scope of %s, see WithRESTMapper: %w", gvk, err)
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameRoot, nil
+}
+
+// defaultNamespace assigns the namespace of owner to obj if it has none and its kind is namespaced
+func (r *Reconciler) defaultNamespace(owner, obj Resource) error {
+	if owner == nil || obj.GetNamespace() != "" {
+		return nil
+	}
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return err
+	}
+	clusterScoped, err := r.isClusterScoped(gvk)
+	if err != nil {
+		return err
+	}
+	if !clusterScoped {
+		obj.SetNamespace(owner.GetNamespace())
+	}
+	return nil
+}
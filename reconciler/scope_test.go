@@ -0,0 +1,79 @@
+package reconciler
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestDefaultNamespace(t *testing.T) {
+	widgetGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	widget := func() Resource {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(widgetGVK)
+		u.SetName("child")
+		return u
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(widgetGVK, meta.RESTScopeRoot)
+
+	tests := []struct {
+		name          string
+		opts          []Option
+		obj           Resource
+		wantNamespace string
+		wantErr       bool
+	}{
+		{
+			name:          "namespaced kind",
+			obj:           &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "child"}},
+			wantNamespace: "ns",
+		},
+		{
+			name:          "namespace set",
+			obj:           &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "child", Namespace: "other"}},
+			wantNamespace: "other",
+		},
+		{
+			name:          "built-in cluster scoped kind",
+			obj:           &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "child"}},
+			wantNamespace: "",
+		},
+		{
+			name:    "kind unknown to the scheme",
+			obj:     widget(),
+			wantErr: true,
+		},
+		{
+			name:          "cluster scoped kind of the RESTMapper",
+			opts:          []Option{WithRESTMapper(mapper)},
+			obj:           widget(),
+			wantNamespace: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(nil, scheme.Scheme, nil, "test", tt.opts...)
+			owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "ns"}}
+			err := r.defaultNamespace(owner, tt.obj)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("defaultNamespace() = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.obj.GetNamespace(); got != tt.wantNamespace {
+				t.Errorf("namespace = %q, want %q", got, tt.wantNamespace)
+			}
+		})
+	}
+}
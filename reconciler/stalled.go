@@ -0,0 +1,61 @@
+package reconciler
+
+import (
+	"github.com/6RiverSystems/operator-toolkit/apis"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// StalledCondition is set when the reconcile of a resource failed with a terminal error. It is cleared
+	// once the generation of the resource changes
+	StalledCondition apis.ConditionType = "Stalled"
+	// TerminalErrorReason is the reason of the Stalled condition set for terminal errors
+	TerminalErrorReason apis.ConditionReason = "TerminalError"
+	// GenerationChangedReason is the reason of the Stalled condition cleared by a change of generation
+	GenerationChangedReason apis.ConditionReason = "GenerationChanged"
+)
+
+// IsStalled returns whether obj carries the Stalled condition for its current generation, in which case
+// reconciling it again is expected to fail the same way
+func IsStalled(obj Resource) bool {
+	getter, ok := obj.(conditionsGetter)
+	if !ok {
+		return false
+	}
+	c := getter.GetConditions().GetCondition(StalledCondition)
+	return c != nil && c.IsTrue() && c.ObservedGeneration == obj.GetGeneration()
+}
+
+// stalledCondition returns the Stalled condition reporting the terminal error of obj
+func stalledCondition(obj Resource, issue error) apis.Condition {
+	return apis.Condition{
+		Type:               StalledCondition,
+		Status:             corev1.ConditionTrue,
+		Reason:             TerminalErrorReason,
+		Message:            issue.Error(),
+		ObservedGeneration: obj.GetGeneration(),
+	}
+}
+
+// clearStalled clears the Stalled condition of obj if it was set for a previous generation.
+// It returns whether the status of obj changed
+func clearStalled(obj Resource) bool {
+	getter, ok := obj.(conditionsGetter)
+	if !ok {
+		return false
+	}
+	c := getter.GetConditions().GetCondition(StalledCondition)
+	if c == nil || !c.IsTrue() || c.ObservedGeneration == obj.GetGeneration() {
+		return false
+	}
+	conditionsStatusAware, ok := obj.(conditionsStatusAware)
+	if !ok {
+		return false
+	}
+	return conditionsStatusAware.SetCondition(apis.Condition{
+		Type:               StalledCondition,
+		Status:             corev1.ConditionFalse,
+		Reason:             GenerationChangedReason,
+		ObservedGeneration: obj.GetGeneration(),
+	})
+}
@@ -0,0 +1,73 @@
+package reconciler
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// statusSnapshotTTL is how long the status of an object is remembered after it was last written
+const statusSnapshotTTL = time.Hour
+
+type statusSnapshot struct {
+	resourceVersion string
+	status          interface{}
+	lastUsed        time.Time
+}
+
+// statusSnapshots remembers the status of each object as returned by the API server on its last write by the
+// Reconciler, along with the resource version it belongs to. Status patches are computed against it
+type statusSnapshots struct {
+	mu        sync.Mutex
+	snapshots map[types.UID]*statusSnapshot
+	lastGC    time.Time
+}
+
+func newStatusSnapshots() *statusSnapshots {
+	return &statusSnapshots{snapshots: map[types.UID]*statusSnapshot{}}
+}
+
+// gc forgets, at most once per ttl, the snapshots unused for longer than the ttl
+func (s *statusSnapshots) gc(now time.Time) {
+	if now.Sub(s.lastGC) < statusSnapshotTTL {
+		return
+	}
+	s.lastGC = now
+	for uid, snapshot := range s.snapshots {
+		if now.Sub(snapshot.lastUsed) >= statusSnapshotTTL {
+			delete(s.snapshots, uid)
+		}
+	}
+}
+
+// record remembers the status of obj at its current resource version
+func (s *statusSnapshots) record(obj Resource) error {
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.gc(now)
+	s.snapshots[obj.GetUID()] = &statusSnapshot{
+		resourceVersion: obj.GetResourceVersion(),
+		status:          runtime.DeepCopyJSONValue(u["status"]),
+		lastUsed:        now,
+	}
+	return nil
+}
+
+// get returns the status of obj at its resource version, if remembered
+func (s *statusSnapshots) get(obj Resource) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot, ok := s.snapshots[obj.GetUID()]
+	if !ok || snapshot.resourceVersion != obj.GetResourceVersion() {
+		return nil, false
+	}
+	snapshot.lastUsed = time.Now()
+	return runtime.DeepCopyJSONValue(snapshot.status), true
+}
@@ -0,0 +1,110 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// statusPatchCounter counts the status patches sent through it
+type statusPatchCounter struct {
+	client.Client
+	patches *int
+}
+
+func (c statusPatchCounter) Status() client.StatusWriter {
+	return statusPatchCounterWriter{StatusWriter: c.Client.Status(), patches: c.patches}
+}
+
+type statusPatchCounterWriter struct {
+	client.StatusWriter
+	patches *int
+}
+
+func (w statusPatchCounterWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	*w.patches++
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+func TestPatchStatus(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "ns", Name: "pod"}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns", UID: "uid"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	patches := 0
+	c := statusPatchCounter{Client: fake.NewFakeClientWithScheme(scheme.Scheme, pod), patches: &patches}
+	r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test")
+
+	obj := &corev1.Pod{}
+	if err := c.Get(ctx, key, obj); err != nil {
+		t.Fatal(err)
+	}
+	cached := obj.DeepCopy()
+	obj.Status.Phase = corev1.PodRunning
+	if err := r.patchStatus(ctx, obj); err != nil {
+		t.Fatal(err)
+	}
+	if patches != 1 {
+		t.Fatalf("%d status patches sent, want 1", patches)
+	}
+
+	// the status is unchanged since the last write
+	if err := r.patchStatus(ctx, obj); err != nil {
+		t.Fatal(err)
+	}
+	if patches != 1 {
+		t.Errorf("%d status patches sent for an unchanged status, want 1", patches)
+	}
+
+	// someone else resets the status, while the cache still holds the object as first read
+	live := &corev1.Pod{}
+	if err := c.Get(ctx, key, live); err != nil {
+		t.Fatal(err)
+	}
+	live.Status.Phase = corev1.PodPending
+	if err := c.Client.Status().Update(ctx, live); err != nil {
+		t.Fatal(err)
+	}
+	cached.Status.Phase = corev1.PodRunning
+	if err := r.patchStatus(ctx, cached); err != nil {
+		t.Fatal(err)
+	}
+	if patches != 2 {
+		t.Errorf("%d status patches sent, want 2", patches)
+	}
+	if err := c.Get(ctx, key, live); err != nil {
+		t.Fatal(err)
+	}
+	if live.Status.Phase != corev1.PodRunning {
+		t.Errorf("phase = %s, want %s", live.Status.Phase, corev1.PodRunning)
+	}
+}
+
+func TestPatchStatusDryRun(t *testing.T) {
+	ctx := context.Background()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns", UID: "uid"}}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, pod)
+	r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test", WithDryRun())
+
+	obj := &corev1.Pod{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pod"}, obj); err != nil {
+		t.Fatal(err)
+	}
+	obj.Status.Phase = corev1.PodRunning
+	if err := r.patchStatus(ctx, obj); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.statuses.get(obj); ok {
+		t.Error("status recorded in dry-run mode")
+	}
+}
@@ -20,3 +20,12 @@ type readyStatusAware interface {
 type conditionsStatusAware interface {
 	SetCondition(apis.Condition) bool
 }
+
+type inventoryStatusAware interface {
+	GetInventory() apis.Inventory
+	SetInventory(inventory apis.Inventory)
+}
+
+type conditionsGetter interface {
+	GetConditions() apis.Conditions
+}
@@ -0,0 +1,116 @@
+package reconciler
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type dryRunKey struct{}
+
+// ContextWithDryRun returns a context overriding the dry-run mode of the Reconciler for the calls made with it
+func ContextWithDryRun(ctx context.Context, dryRun bool) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, dryRun)
+}
+
+// IsDryRun returns whether the writes made with ctx are sent with the dry-run flag and not persisted
+func (r *Reconciler) IsDryRun(ctx context.Context) bool {
+	if dryRun, ok := ctx.Value(dryRunKey{}).(bool); ok {
+		return dryRun
+	}
+	return r.dryRun
+}
+
+// logDryRun logs the change a dry-run write would have made. before is nil for creations
+func (r *Reconciler) logDryRun(op string, before runtime.Object, obj Resource) {
+	log := r.loggerFor(obj).WithValues("operation", op)
+	if before == nil {
+		log.Info("Dry run")
+		return
+	}
+	diff, err := ComputeDiff(before, obj)
+	if err != nil {
+		log.Error(err, "unable to compute dry run diff")
+		return
+	}
+	log.Info("Dry run", "diff", diff.String())
+}
+
+// reportChange logs the field level diff between the found object and the resulting one and,
+// if diff events are enabled, records it as an annotated event on the owner
+func (r *Reconciler) reportChange(ctx context.Context, owner Resource, result OperationResult, before runtime.Object, after Resource) {
+	if r.IsDryRun(ctx) {
+		return
+	}
+	log := r.loggerFor(after)
+	diff, err := ComputeDiff(before, after)
+	if err != nil {
+		log.Error(err, "unable to compute diff")
+		return
+	}
+	if len(diff) == 0 {
+		return
+	}
+	log.Info("Resource changed", "operation", result, "diff", diff.String())
+
+	if r.diffEvents && owner != nil {
+		res := newResourceResult(r.scheme, after, result)
+		r.recorder.AnnotatedEventf(
+			owner,
+			map[string]string{DiffAnnotation: diff.String()},
+			corev1.EventTypeNormal,
+			string(result),
+			"%s %s %s", result, res.GVK.Kind, res.NamespacedName,
+		)
+	}
+}
+
+func (r *Reconciler) create(ctx context.Context, obj Resource) error {
+	if r.IsDryRun(ctx) {
+		r.logDryRun("create", nil, obj)
+		return r.client.Create(ctx, obj, client.DryRunAll)
+	}
+	return r.client.Create(ctx, obj)
+}
+
+func (r *Reconciler) update(ctx context.Context, before runtime.Object, obj Resource) error {
+	if r.IsDryRun(ctx) {
+		r.logDryRun("update", before, obj)
+		return r.client.Update(ctx, obj, client.DryRunAll)
+	}
+	return r.client.Update(ctx, obj)
+}
+
+func (r *Reconciler) patch(ctx context.Context, before runtime.Object, obj Resource, patch client.Patch, opts ...client.PatchOption) error {
+	if r.IsDryRun(ctx) {
+		r.logDryRun("patch", before, obj)
+		opts = append(opts, client.DryRunAll)
+	}
+	return r.client.Patch(ctx, obj, patch, opts...)
+}
+
+func (r *Reconciler) delete(ctx context.Context, obj Resource, opts ...client.DeleteOption) error {
+	if r.IsDryRun(ctx) {
+		r.logDryRun("delete", nil, obj)
+		opts = append(opts, client.DryRunAll)
+	}
+	return r.client.Delete(ctx, obj, opts...)
+}
+
+func (r *Reconciler) writeStatus(ctx context.Context, obj Resource) error {
+	if r.IsDryRun(ctx) {
+		r.logDryRun("status update", nil, obj)
+		return r.client.Status().Update(ctx, obj, client.DryRunAll)
+	}
+	return r.client.Status().Update(ctx, obj)
+}
+
+func (r *Reconciler) writeStatusPatch(ctx context.Context, before runtime.Object, obj Resource, patch client.Patch) error {
+	if r.IsDryRun(ctx) {
+		r.logDryRun("status patch", before, obj)
+		return r.client.Status().Patch(ctx, obj, patch, client.DryRunAll)
+	}
+	return r.client.Status().Patch(ctx, obj, patch)
+}
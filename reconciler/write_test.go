@@ -0,0 +1,70 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// dryRunClient honours the dry-run flag on deletions, as the API server does.
+// The fake client ignores it and deletes the object
+type dryRunClient struct {
+	client.Client
+}
+
+func (c dryRunClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	if len((&client.DeleteOptions{}).ApplyOptions(opts).DryRun) == 0 {
+		return c.Client.Delete(ctx, obj, opts...)
+	}
+	accessor := obj.(Resource)
+	return c.Get(ctx, types.NamespacedName{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}, obj.DeepCopyObject())
+}
+
+func TestDryRunDelete(t *testing.T) {
+	ctx := context.Background()
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"}}
+	c := dryRunClient{fake.NewFakeClientWithScheme(scheme.Scheme, obj.DeepCopy())}
+	r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test", WithDryRun())
+
+	res, err := r.DeleteResourceIfExists(ctx, nil, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != OperationResultDeleted {
+		t.Errorf("result = %q, want %q", res, OperationResultDeleted)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cm"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("resource deleted in dry-run mode: %v", err)
+	}
+}
+
+func TestDryRunIsFinalized(t *testing.T) {
+	ctx := context.Background()
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"}}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, obj.DeepCopy())
+	r := New(c, scheme.Scheme, record.NewFakeRecorder(10), "test", WithDryRun())
+
+	finalized, err := r.IsFinalized(ctx, obj, func() error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !finalized {
+		t.Error("IsFinalized() = false in dry-run mode, want true")
+	}
+
+	live := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cm"}, live); err != nil {
+		t.Fatal(err)
+	}
+	if HasFinalizer(live, "test") {
+		t.Error("finalizer persisted in dry-run mode")
+	}
+}